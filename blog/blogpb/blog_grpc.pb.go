@@ -7,7 +7,6 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
-	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -23,7 +22,10 @@ type BlogServiceClient interface {
 	ReadBlog(ctx context.Context, in *ReadBlogRequest, opts ...grpc.CallOption) (*ReadBlogResponse, error)
 	UpdateBlog(ctx context.Context, in *UpdateBlogRequest, opts ...grpc.CallOption) (*UpdateBlogResponse, error)
 	DeleteBlog(ctx context.Context, in *DeleteBlogRequest, opts ...grpc.CallOption) (*DeleteBlogResponse, error)
-	ListBlog(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (BlogService_ListBlogClient, error)
+	// Exposed over HTTP as newline-delimited JSON. Partial batches are flushed
+	// periodically so slow listings still make progress. This RPC throw an
+	// INVALID_ARGUMENT if batch_size is out of range or resume_token is malformed
+	ListBlog(ctx context.Context, in *ListBlogRequest, opts ...grpc.CallOption) (BlogService_ListBlogClient, error)
 }
 
 type blogServiceClient struct {
@@ -70,7 +72,7 @@ func (c *blogServiceClient) DeleteBlog(ctx context.Context, in *DeleteBlogReques
 	return out, nil
 }
 
-func (c *blogServiceClient) ListBlog(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (BlogService_ListBlogClient, error) {
+func (c *blogServiceClient) ListBlog(ctx context.Context, in *ListBlogRequest, opts ...grpc.CallOption) (BlogService_ListBlogClient, error) {
 	stream, err := c.cc.NewStream(ctx, &BlogService_ServiceDesc.Streams[0], "/blog.BlogService/ListBlog", opts...)
 	if err != nil {
 		return nil, err
@@ -110,7 +112,10 @@ type BlogServiceServer interface {
 	ReadBlog(context.Context, *ReadBlogRequest) (*ReadBlogResponse, error)
 	UpdateBlog(context.Context, *UpdateBlogRequest) (*UpdateBlogResponse, error)
 	DeleteBlog(context.Context, *DeleteBlogRequest) (*DeleteBlogResponse, error)
-	ListBlog(*emptypb.Empty, BlogService_ListBlogServer) error
+	// Exposed over HTTP as newline-delimited JSON. Partial batches are flushed
+	// periodically so slow listings still make progress. This RPC throw an
+	// INVALID_ARGUMENT if batch_size is out of range or resume_token is malformed
+	ListBlog(*ListBlogRequest, BlogService_ListBlogServer) error
 	mustEmbedUnimplementedBlogServiceServer()
 }
 
@@ -130,7 +135,7 @@ func (UnimplementedBlogServiceServer) UpdateBlog(context.Context, *UpdateBlogReq
 func (UnimplementedBlogServiceServer) DeleteBlog(context.Context, *DeleteBlogRequest) (*DeleteBlogResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteBlog not implemented")
 }
-func (UnimplementedBlogServiceServer) ListBlog(*emptypb.Empty, BlogService_ListBlogServer) error {
+func (UnimplementedBlogServiceServer) ListBlog(*ListBlogRequest, BlogService_ListBlogServer) error {
 	return status.Errorf(codes.Unimplemented, "method ListBlog not implemented")
 }
 func (UnimplementedBlogServiceServer) mustEmbedUnimplementedBlogServiceServer() {}
@@ -219,7 +224,7 @@ func _BlogService_DeleteBlog_Handler(srv interface{}, ctx context.Context, dec f
 }
 
 func _BlogService_ListBlog_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(emptypb.Empty)
+	m := new(ListBlogRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
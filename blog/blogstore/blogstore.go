@@ -0,0 +1,58 @@
+// Package blogstore keeps blogs served by BlogService, in mongodb or in
+// memory for tests.
+package blogstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpc-udemy/blog/blogpb"
+)
+
+var (
+	// ErrNotFound is returned for ids of blogs which do not exist.
+	ErrNotFound = errors.New("blog not found")
+	// ErrInvalidID is returned for ids which could not be assigned by store.
+	ErrInvalidID = errors.New("invalid blog id")
+	// ErrInvalidField is returned for names of fields which Update can not
+	// change.
+	ErrInvalidField = errors.New("invalid blog field")
+)
+
+// Store keeps blogs. Ids are assigned by Create and blogs are listed in order
+// of their ids, so listing can continue after any of them. Calls stop when
+// ctx is done.
+type Store interface {
+	Create(ctx context.Context, b *blogpb.Blog) (*blogpb.Blog, error)
+	Read(ctx context.Context, id string) (*blogpb.Blog, error)
+	// Update replaces fields of blog with id of b by fields of b. Fields are
+	// named as in proto file, all of them are replaced if fields is empty and
+	// id is never changed.
+	Update(ctx context.Context, b *blogpb.Blog, fields []string) (*blogpb.Blog, error)
+	Delete(ctx context.Context, id string) error
+	// List calls fn for every blog with id greater than after, or for all
+	// blogs if after is empty. Error of fn stops listing and is returned.
+	List(ctx context.Context, after string, fn func(*blogpb.Blog) error) error
+}
+
+var (
+	_ Store = (*Mongo)(nil)
+	_ Store = (*Memory)(nil)
+)
+
+// updatable maps names of fields which Update changes to the fields.
+var updatable = map[string]func(b *blogpb.Blog) *string{
+	"author_id": func(b *blogpb.Blog) *string { return &b.AuthorId },
+	"title":     func(b *blogpb.Blog) *string { return &b.Title },
+	"content":   func(b *blogpb.Blog) *string { return &b.Content },
+}
+
+// checkFields checks that fields can be passed to Update.
+func checkFields(fields []string) error {
+	for _, f := range fields {
+		if _, ok := updatable[f]; !ok && f != "id" {
+			return fmt.Errorf("%w %q", ErrInvalidField, f)
+		}
+	}
+	return nil
+}
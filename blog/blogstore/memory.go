@@ -0,0 +1,128 @@
+package blogstore
+
+import (
+	"context"
+	"grpc-udemy/blog/blogpb"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
+)
+
+// Memory keeps blogs in memory. Ids have the same format as in Mongo.
+type Memory struct {
+	mu    sync.Mutex
+	blogs map[string]*blogpb.Blog
+}
+
+// NewMemory creates empty store.
+func NewMemory() *Memory {
+	return &Memory{blogs: make(map[string]*blogpb.Blog)}
+}
+
+func (m *Memory) Create(ctx context.Context, b *blogpb.Blog) (*blogpb.Blog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b = &blogpb.Blog{
+		Id:       primitive.NewObjectID().Hex(),
+		AuthorId: b.GetAuthorId(),
+		Title:    b.GetTitle(),
+		Content:  b.GetContent(),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blogs[b.Id] = b
+	return proto.Clone(b).(*blogpb.Blog), nil
+}
+
+func (m *Memory) Read(ctx context.Context, id string) (*blogpb.Blog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := parseID(id); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.blogs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(b).(*blogpb.Blog), nil
+}
+
+func (m *Memory) Update(ctx context.Context, b *blogpb.Blog, fields []string) (*blogpb.Blog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := parseID(b.Id); err != nil {
+		return nil, err
+	}
+	if err := checkFields(fields); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.blogs[b.Id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated := proto.Clone(b).(*blogpb.Blog)
+	if len(fields) > 0 {
+		updated = proto.Clone(old).(*blogpb.Blog)
+		for _, f := range fields {
+			if field, ok := updatable[f]; ok {
+				*field(updated) = *field(b)
+			}
+		}
+	}
+	m.blogs[b.Id] = updated
+	return proto.Clone(updated).(*blogpb.Blog), nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := parseID(id); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.blogs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.blogs, id)
+	return nil
+}
+
+// List calls fn for snapshot of blogs taken when listing starts.
+func (m *Memory) List(ctx context.Context, after string, fn func(*blogpb.Blog) error) error {
+	if after != "" {
+		if _, err := parseID(after); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	var blogs []*blogpb.Blog
+	for id, b := range m.blogs {
+		// hex ids of the same length compare like the ids themselves
+		if id > after {
+			blogs = append(blogs, proto.Clone(b).(*blogpb.Blog))
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(blogs, func(i, j int) bool { return blogs[i].Id < blogs[j].Id })
+
+	for _, b := range blogs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
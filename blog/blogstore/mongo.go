@@ -0,0 +1,173 @@
+package blogstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpc-udemy/blog/blogpb"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type blogItem struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	AuthorId string             `bson:"author_id"`
+	Content  string             `bson:"content"`
+	Title    string             `bson:"title"`
+}
+
+func (b *blogItem) toPb() *blogpb.Blog {
+	return &blogpb.Blog{
+		Id:       b.ID.Hex(),
+		AuthorId: b.AuthorId,
+		Title:    b.Title,
+		Content:  b.Content,
+	}
+}
+
+func parseID(id string) (primitive.ObjectID, error) {
+	oId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%w %q", ErrInvalidID, id)
+	}
+	return oId, nil
+}
+
+// Mongo keeps blogs in mongodb collection.
+type Mongo struct {
+	collection *mongo.Collection
+}
+
+// NewMongo creates store using collection.
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection: collection}
+}
+
+func (m *Mongo) load(ctx context.Context, id primitive.ObjectID) (*blogpb.Blog, error) {
+	var blog blogItem
+	if err := m.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&blog); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed read mongo object id: %w", err)
+	}
+	return blog.toPb(), nil
+}
+
+func (m *Mongo) Create(ctx context.Context, b *blogpb.Blog) (*blogpb.Blog, error) {
+	data := blogItem{
+		AuthorId: b.GetAuthorId(),
+		Title:    b.GetTitle(),
+		Content:  b.GetContent(),
+	}
+	res, err := m.collection.InsertOne(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert into mongo: %w", err)
+	}
+	oId, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, fmt.Errorf("failed parse mongo object id: %v", res.InsertedID)
+	}
+	return m.load(ctx, oId)
+}
+
+func (m *Mongo) Read(ctx context.Context, id string) (*blogpb.Blog, error) {
+	oId, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.load(ctx, oId)
+}
+
+func (m *Mongo) Update(ctx context.Context, b *blogpb.Blog, fields []string) (*blogpb.Blog, error) {
+	oId, err := parseID(b.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFields(fields); err != nil {
+		return nil, err
+	}
+	var res *mongo.UpdateResult
+	if len(fields) == 0 {
+		data := blogItem{
+			ID:       oId,
+			AuthorId: b.AuthorId,
+			Title:    b.Title,
+			Content:  b.Content,
+		}
+		res, err = m.collection.ReplaceOne(ctx, bson.M{"_id": oId}, data)
+	} else {
+		// fields have the same names in documents
+		set := bson.M{}
+		for _, f := range fields {
+			if field, ok := updatable[f]; ok {
+				set[f] = *field(b)
+			}
+		}
+		if len(set) == 0 {
+			return m.load(ctx, oId)
+		}
+		res, err = m.collection.UpdateOne(ctx, bson.M{"_id": oId}, bson.M{"$set": set})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return nil, ErrNotFound
+	}
+	return m.load(ctx, oId)
+}
+
+func (m *Mongo) Delete(ctx context.Context, id string) error {
+	oId, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	res, err := m.collection.DeleteOne(ctx, bson.M{"_id": oId})
+	if err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *Mongo) List(ctx context.Context, after string, fn func(*blogpb.Blog) error) error {
+	filter := bson.M{}
+	if after != "" {
+		oId, err := parseID(after)
+		if err != nil {
+			return err
+		}
+		filter = bson.M{"_id": bson.M{"$gt": oId}}
+	}
+	cursor, err := m.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return fmt.Errorf("failed to list blogs: %w", err)
+	}
+	defer func() {
+		// ctx may be already done, cursor has to be closed anyway
+		if err := cursor.Close(context.Background()); err != nil {
+			log.Printf("failed to close cursor: %v", err)
+		}
+	}()
+
+	for cursor.Next(ctx) {
+		var b blogItem
+		if err := cursor.Decode(&b); err != nil {
+			return fmt.Errorf("failed to decode blog: %w", err)
+		}
+		if err := fn(b.toPb()); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to iterate blogs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package blogstore_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/grpctest"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newMongo(t *testing.T) (*blogstore.Mongo, *mongo.Collection) {
+	m := grpctest.StartMongod(t)
+	collection := m.Client(t).Database("test").Collection("blog")
+	return blogstore.NewMongo(collection), collection
+}
+
+// openCursors returns number of cursors the server keeps open.
+func openCursors(t *testing.T, db *mongo.Database) int64 {
+	t.Helper()
+	var res struct {
+		Metrics struct {
+			Cursor struct {
+				Open struct {
+					Total int64 `bson:"total"`
+				} `bson:"open"`
+			} `bson:"cursor"`
+		} `bson:"metrics"`
+	}
+	if err := db.RunCommand(context.Background(), bson.D{{Key: "serverStatus", Value: 1}}).Decode(&res); err != nil {
+		t.Fatalf("serverStatus: %v", err)
+	}
+	return res.Metrics.Cursor.Open.Total
+}
+
+func TestMongoCRUD(t *testing.T) {
+	store, _ := newMongo(t)
+	ctx := context.Background()
+
+	created, err := store.Create(ctx, &blogpb.Blog{AuthorId: "a", Title: "t", Content: "c"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got, err := store.Read(ctx, created.Id); err != nil || got.Title != "t" {
+		t.Errorf("Read = %v, %v", got, err)
+	}
+	created.Title = "t2"
+	if got, err := store.Update(ctx, created, nil); err != nil || got.Title != "t2" {
+		t.Errorf("Update = %v, %v", got, err)
+	}
+	patch := &blogpb.Blog{Id: created.Id, Content: "c2"}
+	if got, err := store.Update(ctx, patch, []string{"content"}); err != nil || got.Content != "c2" || got.Title != "t2" || got.AuthorId != "a" {
+		t.Errorf("Update of content = %v, %v", got, err)
+	}
+	if _, err := store.Update(ctx, patch, []string{"views"}); !errors.Is(err, blogstore.ErrInvalidField) {
+		t.Errorf("Update of unknown field error = %v, want %v", err, blogstore.ErrInvalidField)
+	}
+	if err := store.Delete(ctx, created.Id); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"read deleted", func() error { _, err := store.Read(ctx, created.Id); return err }, blogstore.ErrNotFound},
+		{"update deleted", func() error { _, err := store.Update(ctx, created, nil); return err }, blogstore.ErrNotFound},
+		{"delete deleted", func() error { return store.Delete(ctx, created.Id) }, blogstore.ErrNotFound},
+		{"read invalid id", func() error { _, err := store.Read(ctx, "x"); return err }, blogstore.ErrInvalidID},
+		{"list after invalid id", func() error { return store.List(ctx, "x", nil) }, blogstore.ErrInvalidID},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMongoListClosesCursor(t *testing.T) {
+	store, collection := newMongo(t)
+	// more documents than the first batch of a cursor, so the server keeps
+	// the cursor open until it is exhausted or closed
+	docs := make([]interface{}, 250)
+	for i := range docs {
+		docs[i] = bson.M{"author_id": "a", "title": fmt.Sprint(i)}
+	}
+	if _, err := collection.InsertMany(context.Background(), docs); err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	errStop := errors.New("stop")
+	tests := []struct {
+		name string
+		stop func(cancel context.CancelFunc) error
+	}{
+		{"callback error", func(context.CancelFunc) error { return errStop }},
+		{"canceled", func(cancel context.CancelFunc) error { cancel(); return nil }},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		var open int64
+		err := store.List(ctx, "", func(*blogpb.Blog) error {
+			if open == 0 {
+				open = openCursors(t, collection.Database())
+			}
+			return tt.stop(cancel)
+		})
+		cancel()
+		if err == nil {
+			t.Errorf("%s: List finished all blogs", tt.name)
+		}
+		if open != 1 {
+			t.Errorf("%s: %d open cursors while listing, want 1", tt.name, open)
+		}
+		if got := openCursors(t, collection.Database()); got != 0 {
+			t.Errorf("%s: %d open cursors after listing, want 0", tt.name, got)
+		}
+	}
+
+	var n int
+	if err := store.List(context.Background(), "", func(*blogpb.Blog) error { n++; return nil }); err != nil || n != len(docs) {
+		t.Errorf("List = %d blogs, %v, want %d", n, err, len(docs))
+	}
+	if got := openCursors(t, collection.Database()); got != 0 {
+		t.Errorf("%d open cursors after full listing, want 0", got)
+	}
+}
@@ -0,0 +1,209 @@
+// Package blogsvc implements BlogService on top of a blogstore.Store.
+package blogsvc
+
+import (
+	"context"
+	"errors"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/deadline"
+	"grpc-udemy/streaming"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Config configures Server, zero values other than Store select defaults.
+type Config struct {
+	Store blogstore.Store
+	// ListFlushInterval is how long ListBlog holds a partial batch before
+	// sending it.
+	ListFlushInterval time.Duration
+	// Deadline bounds deadlines of unary calls, ListDeadline of ListBlog.
+	Deadline     deadline.Limit
+	ListDeadline deadline.Limit
+}
+
+// Server implements blogpb.BlogServiceServer.
+type Server struct {
+	blogpb.UnimplementedBlogServiceServer
+	store             blogstore.Store
+	listFlushInterval time.Duration
+	deadline          deadline.Limit
+	listDeadline      deadline.Limit
+}
+
+// New creates Server configured by cfg.
+func New(cfg Config) *Server {
+	if cfg.ListFlushInterval <= 0 {
+		cfg.ListFlushInterval = 100 * time.Millisecond
+	}
+	return &Server{
+		store:             cfg.Store,
+		listFlushInterval: cfg.ListFlushInterval,
+		deadline:          cfg.Deadline,
+		listDeadline:      cfg.ListDeadline,
+	}
+}
+
+// ServerOptions returns server options enforcing deadlines, so calls stuck
+// on unavailable store do not pile up.
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	c := deadline.Config{
+		Unary: s.deadline,
+		Methods: map[string]deadline.Limit{
+			"/blog.BlogService/ListBlog": s.listDeadline,
+		},
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(deadline.UnaryServerInterceptor(c)),
+		grpc.ChainStreamInterceptor(deadline.StreamServerInterceptor(c)),
+	}
+}
+
+// storeError returns status error of failed store call, calls interrupted by
+// ctx fail with CANCELED or DEADLINE_EXCEEDED.
+func storeError(ctx context.Context, err error, msg string) error {
+	switch {
+	case ctx.Err() != nil:
+		return streaming.Error(ctx.Err())
+	case errors.Is(err, blogstore.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, blogstore.ErrInvalidID), errors.Is(err, blogstore.ErrInvalidField):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	deadline.Logf(ctx, "%s: %v", msg, err)
+	return status.Error(codes.Internal, msg)
+}
+
+func (s *Server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
+	blog, err := s.store.Read(ctx, req.Id)
+	if err != nil {
+		return nil, storeError(ctx, err, "failed to read blog")
+	}
+	return &blogpb.ReadBlogResponse{Blog: blog}, nil
+}
+
+func (s *Server) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
+	blog, err := s.store.Create(ctx, req.GetBlog())
+	if err != nil {
+		return nil, storeError(ctx, err, "failed to create blog")
+	}
+	return &blogpb.CreateBlogResponse{Blog: blog}, nil
+}
+
+func (s *Server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
+	if req.Blog == nil {
+		return nil, status.Error(codes.InvalidArgument, "blog is required")
+	}
+	var blog *blogpb.Blog
+	var err error
+	if req.UpdateMask != nil && len(req.UpdateMask.Paths) == 0 {
+		// mask without fields, e.g. from PATCH with empty body, changes nothing
+		blog, err = s.store.Read(ctx, req.Blog.Id)
+	} else {
+		blog, err = s.store.Update(ctx, req.Blog, req.UpdateMask.GetPaths())
+	}
+	if err != nil {
+		return nil, storeError(ctx, err, "failed to update blog")
+	}
+	return &blogpb.UpdateBlogResponse{Blog: blog}, nil
+}
+
+func (s *Server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
+	if err := s.store.Delete(ctx, req.Id); err != nil {
+		return nil, storeError(ctx, err, "failed to delete blog")
+	}
+	return &blogpb.DeleteBlogResponse{Id: req.Id}, nil
+}
+
+const (
+	// defaultListBatchSize is number of blogs sent in single ListBlog message
+	// unless client asks otherwise.
+	defaultListBatchSize = 2
+	maxListBatchSize     = 1000
+	// maxListMessageBytes is default maximum message size accepted by gRPC
+	// clients, adaptive batches stay under it.
+	maxListMessageBytes = 4 << 20
+
+	// listKind names ListBlog resume tokens.
+	listKind = "blog-list"
+)
+
+// listTokenBytes is size of resume_token field of ListBlog messages, which
+// adaptive batches leave room for. Stores assign ids as hex of 12-byte mongo
+// object ids.
+var listTokenBytes = protowire.SizeTag(2) + protowire.SizeBytes(len(streaming.ResumeToken(listKind, strings.Repeat("0", 24))))
+
+// listBatchConfig returns batching of ListBlog stream requested by req.
+func (s *Server) listBatchConfig(req *blogpb.ListBlogRequest) (streaming.BatchConfig, error) {
+	if req.BatchSize < 0 || req.BatchSize > maxListBatchSize {
+		return streaming.BatchConfig{}, status.Errorf(codes.InvalidArgument, "batch_size must be between 0 (server default) and %d, got %d", maxListBatchSize, req.BatchSize)
+	}
+	cfg := streaming.BatchConfig{MaxItems: int(req.BatchSize), Interval: s.listFlushInterval}
+	if req.Adaptive {
+		cfg.MaxBytes = maxListMessageBytes - listTokenBytes
+		cfg.Size = func(item interface{}) int {
+			// size of the blog as element of repeated field 1
+			n := proto.Size(item.(*blogpb.Blog))
+			return protowire.SizeTag(1) + protowire.SizeBytes(n)
+		}
+		if cfg.MaxItems == 0 {
+			cfg.MaxItems = maxListBatchSize
+		}
+	} else if cfg.MaxItems == 0 {
+		cfg.MaxItems = defaultListBatchSize
+	}
+	return cfg, nil
+}
+
+// listPosition returns id of the blog resume token points to, or empty
+// string if token is empty.
+func listPosition(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	return streaming.ParseResumeToken(listKind, token)
+}
+
+func (s *Server) ListBlog(req *blogpb.ListBlogRequest, stream blogpb.BlogService_ListBlogServer) error {
+	cfg, err := s.listBatchConfig(req)
+	if err != nil {
+		return err
+	}
+	after, err := listPosition(req.ResumeToken)
+	if err != nil {
+		return err
+	}
+
+	batcher := streaming.NewBatcher(cfg, func(items []interface{}) error {
+		res := &blogpb.ListBlogResponse{Blog: make([]*blogpb.Blog, len(items))}
+		for i, item := range items {
+			res.Blog[i] = item.(*blogpb.Blog)
+		}
+		res.ResumeToken = streaming.ResumeToken(listKind, res.Blog[len(res.Blog)-1].Id)
+		return streaming.Send(stream, res)
+	})
+	defer batcher.Stop()
+	ctx := stream.Context()
+	var sendErr error
+	err = s.store.List(ctx, after, func(b *blogpb.Blog) error {
+		sendErr = batcher.Add(b)
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if errors.Is(err, blogstore.ErrInvalidID) {
+		return status.Error(codes.InvalidArgument, "malformed resume token")
+	}
+	if err != nil {
+		return storeError(ctx, err, "failed to list blogs")
+	}
+	return batcher.Close()
+}
@@ -0,0 +1,511 @@
+package blogsvc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/blog/blogsvc"
+	"grpc-udemy/deadline"
+	"grpc-udemy/grpctest"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// stubStore overrides methods of memory store.
+type stubStore struct {
+	*blogstore.Memory
+	read func(ctx context.Context, id string) (*blogpb.Blog, error)
+	list func(ctx context.Context, after string, fn func(*blogpb.Blog) error) error
+}
+
+func (s *stubStore) Read(ctx context.Context, id string) (*blogpb.Blog, error) {
+	if s.read != nil {
+		return s.read(ctx, id)
+	}
+	return s.Memory.Read(ctx, id)
+}
+
+func (s *stubStore) List(ctx context.Context, after string, fn func(*blogpb.Blog) error) error {
+	if s.list != nil {
+		return s.list(ctx, after, fn)
+	}
+	return s.Memory.List(ctx, after, fn)
+}
+
+func createBlogs(t testing.TB, client blogpb.BlogServiceClient, n int) []*blogpb.Blog {
+	t.Helper()
+	var blogs []*blogpb.Blog
+	for i := 0; i < n; i++ {
+		res, err := client.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{
+			AuthorId: "john",
+			Title:    fmt.Sprintf("blog%d", i),
+		}})
+		if err != nil {
+			t.Fatalf("CreateBlog: %v", err)
+		}
+		blogs = append(blogs, res.Blog)
+	}
+	return blogs
+}
+
+func listBlogs(client blogpb.BlogServiceClient, req *blogpb.ListBlogRequest) ([]*blogpb.ListBlogResponse, error) {
+	stream, err := client.ListBlog(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	var res []*blogpb.ListBlogResponse
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, r)
+	}
+}
+
+func batchSizes(res []*blogpb.ListBlogResponse) []int {
+	var sizes []int
+	for _, r := range res {
+		sizes = append(sizes, len(r.Blog))
+	}
+	return sizes
+}
+
+func TestCRUD(t *testing.T) {
+	testCRUD(t, grpctest.Blog(t, blogsvc.Config{}))
+}
+
+func testCRUD(t *testing.T, client blogpb.BlogServiceClient) {
+	ctx := context.Background()
+
+	created, err := client.CreateBlog(ctx, &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{AuthorId: "john", Title: "first", Content: "hello"}})
+	if err != nil {
+		t.Fatalf("CreateBlog: %v", err)
+	}
+	blog := created.Blog
+	if blog.Id == "" || blog.Title != "first" || blog.Content != "hello" {
+		t.Fatalf("CreateBlog = %v", blog)
+	}
+
+	read, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id})
+	if err != nil || !proto.Equal(read.Blog, blog) {
+		t.Fatalf("ReadBlog = %v, %v, want %v", read, err, blog)
+	}
+
+	blog.Title = "updated"
+	updated, err := client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: blog})
+	if err != nil || !proto.Equal(updated.Blog, blog) {
+		t.Fatalf("UpdateBlog = %v, %v, want %v", updated, err, blog)
+	}
+	read, err = client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id})
+	if err != nil || read.Blog.Title != "updated" {
+		t.Fatalf("ReadBlog after update = %v, %v", read, err)
+	}
+
+	// only masked fields are updated
+	updated, err = client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{
+		Blog:       &blogpb.Blog{Id: blog.Id, Title: "patched", AuthorId: "ignored"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	})
+	blog.Title = "patched"
+	if err != nil || !proto.Equal(updated.Blog, blog) {
+		t.Fatalf("UpdateBlog of title = %v, %v, want %v", updated, err, blog)
+	}
+	read, err = client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id})
+	if err != nil || !proto.Equal(read.Blog, blog) {
+		t.Fatalf("ReadBlog after update of title = %v, %v, want %v", read, err, blog)
+	}
+
+	deleted, err := client.DeleteBlog(ctx, &blogpb.DeleteBlogRequest{Id: blog.Id})
+	if err != nil || deleted.Id != blog.Id {
+		t.Fatalf("DeleteBlog = %v, %v", deleted, err)
+	}
+	if _, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("ReadBlog of deleted blog error = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	testErrors(t, grpctest.Blog(t, blogsvc.Config{}))
+}
+
+func testErrors(t *testing.T, client blogpb.BlogServiceClient) {
+	ctx := context.Background()
+	missing := "5f1d7f6b2b0e3c1a2c3d4e5f"
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"read invalid id", func() error {
+			_, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: "nope"})
+			return err
+		}, codes.InvalidArgument},
+		{"read missing", func() error {
+			_, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: missing})
+			return err
+		}, codes.NotFound},
+		{"update without blog", func() error {
+			_, err := client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"update missing", func() error {
+			_, err := client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: missing}})
+			return err
+		}, codes.NotFound},
+		{"update missing with mask", func() error {
+			_, err := client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: missing}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}}})
+			return err
+		}, codes.NotFound},
+		{"update unknown field", func() error {
+			_, err := client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: missing}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"views"}}})
+			return err
+		}, codes.InvalidArgument},
+		{"delete invalid id", func() error {
+			_, err := client.DeleteBlog(ctx, &blogpb.DeleteBlogRequest{Id: "nope"})
+			return err
+		}, codes.InvalidArgument},
+		{"delete missing", func() error {
+			_, err := client.DeleteBlog(ctx, &blogpb.DeleteBlogRequest{Id: missing})
+			return err
+		}, codes.NotFound},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); status.Code(err) != tt.want {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestStoreFailure(t *testing.T) {
+	store := &stubStore{Memory: blogstore.NewMemory(), read: func(ctx context.Context, id string) (*blogpb.Blog, error) {
+		return nil, errors.New("connection refused")
+	}}
+	client := grpctest.Blog(t, blogsvc.Config{Store: store})
+	_, err := client.ReadBlog(context.Background(), &blogpb.ReadBlogRequest{Id: "5f1d7f6b2b0e3c1a2c3d4e5f"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("error = %v, want INTERNAL", err)
+	}
+	if msg := status.Convert(err).Message(); msg != "failed to read blog" {
+		t.Errorf("store error leaked to client: %q", msg)
+	}
+}
+
+// blockingRead waits until ctx is done.
+func blockingRead(ctx context.Context, id string) (*blogpb.Blog, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDeadlines(t *testing.T) {
+	store := &stubStore{Memory: blogstore.NewMemory(), read: blockingRead}
+	client := grpctest.Blog(t, blogsvc.Config{
+		Store:    store,
+		Deadline: deadline.Limit{Default: 20 * time.Millisecond, Max: time.Second},
+	})
+	req := &blogpb.ReadBlogRequest{Id: "5f1d7f6b2b0e3c1a2c3d4e5f"}
+
+	// server default applies to calls without deadline
+	start := time.Now()
+	if _, err := client.ReadBlog(context.Background(), req); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("error = %v, want DEADLINE_EXCEEDED", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("call was not stopped by default deadline")
+	}
+
+	// store calls stop when client cancels
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := client.ReadBlog(ctx, req); status.Code(err) != codes.Canceled {
+		t.Errorf("error = %v, want CANCELED", err)
+	}
+}
+
+func TestListBatches(t *testing.T) {
+	// batches are never flushed by timer
+	client := grpctest.Blog(t, blogsvc.Config{ListFlushInterval: time.Hour})
+	blogs := createBlogs(t, client, 5)
+
+	tests := []struct {
+		req  *blogpb.ListBlogRequest
+		want []int
+	}{
+		{&blogpb.ListBlogRequest{}, []int{2, 2, 1}},
+		{&blogpb.ListBlogRequest{BatchSize: 3}, []int{3, 2}},
+		{&blogpb.ListBlogRequest{Adaptive: true}, []int{5}},
+		{&blogpb.ListBlogRequest{Adaptive: true, BatchSize: 4}, []int{4, 1}},
+	}
+	for _, tt := range tests {
+		res, err := listBlogs(client, tt.req)
+		if err != nil {
+			t.Fatalf("ListBlog(%v): %v", tt.req, err)
+		}
+		if got := batchSizes(res); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ListBlog(%v) batches = %v, want %v", tt.req, got, tt.want)
+		}
+		var i int
+		for _, r := range res {
+			for _, b := range r.Blog {
+				if !proto.Equal(b, blogs[i]) {
+					t.Errorf("blog %d = %v, want %v", i, b, blogs[i])
+				}
+				i++
+			}
+		}
+	}
+
+	for _, size := range []int32{-1, 1001} {
+		if _, err := listBlogs(client, &blogpb.ListBlogRequest{BatchSize: size}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("batch_size %d: error = %v, want INVALID_ARGUMENT", size, err)
+		}
+	}
+}
+
+func TestListAdaptiveLimit(t *testing.T) {
+	client := grpctest.Blog(t, blogsvc.Config{ListFlushInterval: time.Hour})
+
+	// content makes each blog take exactly a quarter of the default 4 MiB
+	// message limit as element of the repeated field
+	const quarter = 1 << 20
+	blog := &blogpb.Blog{Id: "5f1d7f6b2b0e3c1a2c3d4e5f"}
+	for n := quarter - 64; ; n++ {
+		blog.Content = strings.Repeat("x", n)
+		size := proto.Size(blog)
+		if size+protowire.SizeVarint(uint64(size))+1 >= quarter {
+			break
+		}
+	}
+	if size := proto.Size(blog); size+protowire.SizeVarint(uint64(size))+1 != quarter {
+		t.Fatalf("blog element takes %d bytes, want %d", size+protowire.SizeVarint(uint64(size))+1, quarter)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := client.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{Content: blog.Content}}); err != nil {
+			t.Fatalf("CreateBlog: %v", err)
+		}
+	}
+
+	// four blogs fill the limit, so the resume token does not fit with them
+	res, err := listBlogs(client, &blogpb.ListBlogRequest{Adaptive: true})
+	if err != nil {
+		t.Fatalf("ListBlog: %v", err)
+	}
+	if got := batchSizes(res); fmt.Sprint(got) != "[3 1]" {
+		t.Errorf("batches = %v, want [3 1]", got)
+	}
+	for _, r := range res {
+		if size := proto.Size(r); size > 4<<20 {
+			t.Errorf("message of %d blogs takes %d bytes, more than 4 MiB", len(r.Blog), size)
+		}
+	}
+}
+
+func TestListEmpty(t *testing.T) {
+	client := grpctest.Blog(t, blogsvc.Config{})
+	res, err := listBlogs(client, &blogpb.ListBlogRequest{})
+	if err != nil || len(res) != 0 {
+		t.Errorf("ListBlog = %v, %v, want no messages", res, err)
+	}
+}
+
+func TestListResume(t *testing.T) {
+	testListResume(t, grpctest.Blog(t, blogsvc.Config{}))
+}
+
+func testListResume(t *testing.T, client blogpb.BlogServiceClient) {
+	blogs := createBlogs(t, client, 5)
+
+	first, err := listBlogs(client, &blogpb.ListBlogRequest{})
+	if err != nil {
+		t.Fatalf("ListBlog: %v", err)
+	}
+	res, err := listBlogs(client, &blogpb.ListBlogRequest{ResumeToken: first[0].ResumeToken})
+	if err != nil {
+		t.Fatalf("resumed ListBlog: %v", err)
+	}
+	var ids []string
+	for _, r := range res {
+		for _, b := range r.Blog {
+			ids = append(ids, b.Id)
+		}
+	}
+	want := []string{blogs[2].Id, blogs[3].Id, blogs[4].Id}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("resumed ids = %v, want %v", ids, want)
+	}
+
+	// blogs created after the token are listed as well
+	last := first[len(first)-1].ResumeToken
+	more := createBlogs(t, client, 1)
+	res, err = listBlogs(client, &blogpb.ListBlogRequest{ResumeToken: last})
+	if err != nil || len(res) != 1 || res[0].Blog[0].Id != more[0].Id {
+		t.Errorf("ListBlog after last token = %v, %v, want %v", res, err, more[0])
+	}
+
+	for _, token := range []string{"garbage!", "Z3JlZXQtbWFueTox"} {
+		if _, err := listBlogs(client, &blogpb.ListBlogRequest{ResumeToken: token}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("token %q: error = %v, want INVALID_ARGUMENT", token, err)
+		}
+	}
+}
+
+func TestListFlushInterval(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	store := &stubStore{Memory: blogstore.NewMemory()}
+	store.list = func(ctx context.Context, after string, fn func(*blogpb.Blog) error) error {
+		if err := fn(&blogpb.Blog{Id: "5f1d7f6b2b0e3c1a2c3d4e5f"}); err != nil {
+			return err
+		}
+		// slow store, the partial batch must not wait for it
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+	client := grpctest.Blog(t, blogsvc.Config{Store: store, ListFlushInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.ListBlog(ctx, &blogpb.ListBlogRequest{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ListBlog: %v", err)
+	}
+	res, err := stream.Recv()
+	if err != nil || len(res.Blog) != 1 {
+		t.Fatalf("first message = %v, %v, want partial batch", res, err)
+	}
+}
+
+func TestListCanceled(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	store := &stubStore{Memory: blogstore.NewMemory()}
+	store.list = func(ctx context.Context, after string, fn func(*blogpb.Blog) error) error {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	}
+	client := grpctest.Blog(t, blogsvc.Config{Store: store})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.ListBlog(ctx, &blogpb.ListBlogRequest{})
+	if err != nil {
+		t.Fatalf("ListBlog: %v", err)
+	}
+	<-started
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("Recv error = %v, want CANCELED", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("store listing was not stopped")
+	}
+}
+
+// lateStream reports use of the stream after the handler returned.
+type lateStream struct {
+	grpc.ServerStream
+	returned *int32
+	late     chan<- string
+}
+
+func (s *lateStream) used(method string) {
+	if atomic.LoadInt32(s.returned) == 1 {
+		select {
+		case s.late <- method:
+		default:
+		}
+	}
+}
+
+func (s *lateStream) Context() context.Context {
+	s.used("Context")
+	return s.ServerStream.Context()
+}
+
+func (s *lateStream) SendMsg(m interface{}) error {
+	s.used("SendMsg")
+	return s.ServerStream.SendMsg(m)
+}
+
+func TestListStoreFailure(t *testing.T) {
+	store := &stubStore{Memory: blogstore.NewMemory()}
+	store.list = func(ctx context.Context, after string, fn func(*blogpb.Blog) error) error {
+		if err := fn(&blogpb.Blog{Id: "5f1d7f6b2b0e3c1a2c3d4e5f"}); err != nil {
+			return err
+		}
+		return errors.New("connection reset")
+	}
+	svc := blogsvc.New(blogsvc.Config{Store: store, ListFlushInterval: 10 * time.Millisecond})
+	late := make(chan string, 1)
+	s := grpc.NewServer(append(svc.ServerOptions(), grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			var returned int32
+			defer atomic.StoreInt32(&returned, 1)
+			return handler(srv, &lateStream{ServerStream: ss, returned: &returned, late: late})
+		}))...)
+	blogpb.RegisterBlogServiceServer(s, svc)
+	client := blogpb.NewBlogServiceClient(grpctest.Serve(t, s))
+
+	// partial batch waits for the flush timer when the store fails
+	res, err := listBlogs(client, &blogpb.ListBlogRequest{BatchSize: 10})
+	if status.Code(err) != codes.Internal || len(res) != 0 {
+		t.Errorf("ListBlog = %v, %v, want INTERNAL and no blogs", res, err)
+	}
+	select {
+	case method := <-late:
+		t.Errorf("stream %s called after ListBlog returned", method)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func BenchmarkReadBlog(b *testing.B) {
+	client := grpctest.Blog(b, blogsvc.Config{})
+	req := &blogpb.ReadBlogRequest{Id: createBlogs(b, client, 1)[0].Id}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.ReadBlog(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkListBlog(b *testing.B) {
+	client := grpctest.Blog(b, blogsvc.Config{})
+	createBlogs(b, client, 1000)
+	for _, req := range []*blogpb.ListBlogRequest{{BatchSize: 10}, {BatchSize: 100}, {Adaptive: true}} {
+		b.Run(fmt.Sprintf("batch=%d,adaptive=%v", req.BatchSize, req.Adaptive), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := listBlogs(client, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
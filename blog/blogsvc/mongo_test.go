@@ -0,0 +1,124 @@
+package blogsvc_test
+
+import (
+	"context"
+	"fmt"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/blog/blogsvc"
+	"grpc-udemy/grpctest"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mongoBlog starts blog service on collection of m.
+func mongoBlog(t *testing.T, m *grpctest.Mongod, collection string) blogpb.BlogServiceClient {
+	store := blogstore.NewMongo(m.Client(t).Database("test").Collection(collection))
+	return grpctest.Blog(t, blogsvc.Config{Store: store})
+}
+
+func TestMongo(t *testing.T) {
+	m := grpctest.StartMongod(t)
+	t.Run("CRUD", func(t *testing.T) { testCRUD(t, mongoBlog(t, m, "crud")) })
+	t.Run("Errors", func(t *testing.T) { testErrors(t, mongoBlog(t, m, "errors")) })
+	t.Run("ListResume", func(t *testing.T) { testListResume(t, mongoBlog(t, m, "resume")) })
+}
+
+func TestMongoConcurrentUpdates(t *testing.T) {
+	client := mongoBlog(t, grpctest.StartMongod(t), "blog")
+	ctx := context.Background()
+	blog := createBlogs(t, client, 1)[0]
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := client.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{
+				Id:       blog.Id,
+				AuthorId: fmt.Sprintf("author%d", i),
+				Title:    fmt.Sprintf("title%d", i),
+			}})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("UpdateBlog: %v", err)
+		}
+	}
+
+	// updates replace the whole blog, so fields must come from one of them
+	res, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id})
+	if err != nil {
+		t.Fatalf("ReadBlog: %v", err)
+	}
+	var i int
+	if _, err := fmt.Sscanf(res.Blog.Title, "title%d", &i); err != nil || res.Blog.AuthorId != fmt.Sprintf("author%d", i) {
+		t.Errorf("blog after concurrent updates = %v, mixes different updates", res.Blog)
+	}
+
+	// concurrent delete and update: exactly one delete wins, update either
+	// succeeds first or finds nothing
+	var deleted, notFound int
+	var mu sync.Mutex
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.DeleteBlog(ctx, &blogpb.DeleteBlogRequest{Id: blog.Id})
+			mu.Lock()
+			defer mu.Unlock()
+			switch status.Code(err) {
+			case codes.OK:
+				deleted++
+			case codes.NotFound:
+				notFound++
+			default:
+				t.Errorf("DeleteBlog: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if deleted != 1 || notFound != 4 {
+		t.Errorf("concurrent deletes: %d succeeded and %d not found, want 1 and 4", deleted, notFound)
+	}
+}
+
+func TestMongoRestart(t *testing.T) {
+	m := grpctest.StartMongod(t)
+	client := mongoBlog(t, m, "blog")
+	blog := createBlogs(t, client, 1)[0]
+
+	m.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	// depending on when the driver notices, the call either fails on the
+	// broken connection or waits for a server until the deadline
+	_, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id})
+	if code := status.Code(err); code != codes.DeadlineExceeded && code != codes.Internal {
+		t.Errorf("ReadBlog while mongod is down error = %v, want DEADLINE_EXCEEDED or INTERNAL", err)
+	}
+
+	// the service reconnects on its own and the data survives the restart
+	m.Start()
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: blog.Id})
+	if err != nil || res.Blog.Title != blog.Title {
+		t.Fatalf("ReadBlog after restart = %v, %v, want %v", res, err, blog)
+	}
+	more := createBlogs(t, client, 1)
+	list, err := listBlogs(client, &blogpb.ListBlogRequest{})
+	if err != nil || len(list) != 1 || len(list[0].Blog) != 2 || list[0].Blog[1].Id != more[0].Id {
+		t.Errorf("ListBlog after restart = %v, %v", list, err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"grpc-udemy/blog/blogpb"
+	"log"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newGateway creates HTTP/JSON gateway which proxies REST calls to gRPC server
+// listening on grpcAddr. ListBlog stream is served as newline-delimited JSON.
+func newGateway(ctx context.Context, grpcAddr, httpAddr string) (*http.Server, error) {
+	conn, err := grpc.DialContext(ctx, grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to dial blog service: %v", err)
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	handler, err := gatewayHandler(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Server{Addr: httpAddr, Handler: handler}, nil
+}
+
+// gatewayHandler serves REST calls by calling blog service over conn.
+func gatewayHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux()
+	if err := blogpb.RegisterBlogServiceHandler(ctx, mux, conn); err != nil {
+		log.Printf("failed to register blog gateway: %v", err)
+		return nil, err
+	}
+	return mux, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/blog/blogsvc"
+	"grpc-udemy/grpctest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestGateway(t *testing.T) http.Handler {
+	svc := blogsvc.New(blogsvc.Config{Store: blogstore.NewMemory()})
+	s := grpc.NewServer(svc.ServerOptions()...)
+	blogpb.RegisterBlogServiceServer(s, svc)
+	handler, err := gatewayHandler(context.Background(), grpctest.Serve(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handler
+}
+
+// call makes REST call and decodes response into res.
+func call(t *testing.T, h http.Handler, method, path, body string, res proto.Message) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if w.Code == http.StatusOK {
+		if err := protojson.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%s %s: invalid response %s: %v", method, path, w.Body, err)
+		}
+	}
+	return w.Code
+}
+
+func TestGatewayPatch(t *testing.T) {
+	h := newTestGateway(t)
+
+	var created blogpb.CreateBlogResponse
+	if code := call(t, h, "POST", "/v1/blogs", `{"author_id": "john", "title": "first", "content": "hello"}`, &created); code != http.StatusOK {
+		t.Fatalf("POST = %d", code)
+	}
+	want := created.Blog
+
+	tests := []struct {
+		body   string
+		update func(b *blogpb.Blog)
+	}{
+		{`{"title": "updated"}`, func(b *blogpb.Blog) { b.Title = "updated" }},
+		{`{"content": "bye", "authorId": "jane"}`, func(b *blogpb.Blog) { b.Content, b.AuthorId = "bye", "jane" }},
+		{`{"content": ""}`, func(b *blogpb.Blog) { b.Content = "" }},
+		// changes nothing, id is taken from the path
+		{`{}`, func(b *blogpb.Blog) {}},
+		{`{"id": "5f1d7f6b2b0e3c1a2c3d4e5f"}`, func(b *blogpb.Blog) {}},
+	}
+	for _, tt := range tests {
+		tt.update(want)
+		var updated blogpb.UpdateBlogResponse
+		if code := call(t, h, "PATCH", "/v1/blogs/"+want.Id, tt.body, &updated); code != http.StatusOK || !proto.Equal(updated.Blog, want) {
+			t.Errorf("PATCH %s = %d, %v, want %v", tt.body, code, updated.Blog, want)
+		}
+		var read blogpb.ReadBlogResponse
+		if code := call(t, h, "GET", "/v1/blogs/"+want.Id, "", &read); code != http.StatusOK || !proto.Equal(read.Blog, want) {
+			t.Errorf("GET after PATCH %s = %d, %v, want %v", tt.body, code, read.Blog, want)
+		}
+	}
+
+	if code := call(t, h, "PATCH", "/v1/blogs/"+want.Id, `{"views": 1}`, nil); code != http.StatusBadRequest {
+		t.Errorf("PATCH of unknown field = %d, want %d", code, http.StatusBadRequest)
+	}
+}
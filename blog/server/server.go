@@ -2,178 +2,26 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/blog/blogsvc"
+	"grpc-udemy/deadline"
+	"grpc-udemy/web"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-var (
-	collection *mongo.Collection
-)
-
-type server struct {
-	blogpb.UnimplementedBlogServiceServer
-}
-
-func (s *server) loadBlogItem(id primitive.ObjectID) (*blogItem, error) {
-	var blog blogItem
-
-	if err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&blog); err != nil {
-		log.Printf("failed read mongo object id: %v\n", err)
-		return nil, err
-	}
-
-	return &blog, nil
-}
-
-func (s *server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
-	oId, err := primitive.ObjectIDFromHex(req.Id)
-	if err != nil {
-		log.Printf("failed to parse object id: %v\n", req.Id)
-		return nil, status.Errorf(codes.Internal, "failed to parse object id %v", req.Id)
-	}
-
-	blog, err := s.loadBlogItem(oId)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-
-	return &blogpb.ReadBlogResponse{Blog: blog.toPb()}, nil
-}
-
-func (s *server) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
-	blogReq := req.GetBlog()
-	data := blogItem{
-		AuthorId: blogReq.AuthorId,
-		Title:    blogReq.Title,
-		Content:  blogReq.Content,
-	}
-
-	res, err := collection.InsertOne(context.Background(), data)
-	if err != nil {
-		log.Printf("failed to insert into mongo: %v\n", err)
-		return nil, status.Errorf(codes.Internal, "internal error %v", err)
-	}
-
-	oId, ok := res.InsertedID.(primitive.ObjectID)
-	if !ok {
-		log.Printf("failed parse mongo object id: %v\n", res.InsertedID)
-		return nil, status.Errorf(codes.Internal, "internal error")
-	}
-
-	blogRes, err := s.loadBlogItem(oId)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-
-	return &blogpb.CreateBlogResponse{
-		Blog: blogRes.toPb(),
-	}, nil
-}
-
-func (s *server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
-	oId, err := primitive.ObjectIDFromHex(req.Blog.Id)
-	if err != nil {
-		log.Printf("failed to parse object id: %v\n", req.Blog.Id)
-		return nil, status.Errorf(codes.Internal, "failed to parse object id %v", req.Blog.Id)
-	}
-	_, err = collection.ReplaceOne(context.Background(), bson.M{"_id": oId}, req.Blog)
-	if err != nil {
-		log.Printf("failed to update: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to update object id %v", req.Blog.Id)
-	}
-
-	blog, err := s.loadBlogItem(oId)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-	return &blogpb.UpdateBlogResponse{Blog: blog.toPb()}, nil
-}
-
-func (s *server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
-	oId, err := primitive.ObjectIDFromHex(req.Id)
-	if err != nil {
-		log.Printf("failed to parse object id: %v\n", req.Id)
-		return nil, status.Errorf(codes.Internal, "failed to parse object id %v", req.Id)
-	}
-
-	if _, err = collection.DeleteOne(context.Background(), &bson.M{"_id": oId}); err != nil {
-		log.Printf("failed to deletew: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to delete object id %v", req.Id)
-	}
-
-	return &blogpb.DeleteBlogResponse{Id: oId.Hex()}, nil
-}
-
-func (s *server) ListBlog(in *emptypb.Empty, stream blogpb.BlogService_ListBlogServer) error {
-	cursor, err := collection.Find(context.Background(), &bson.M{})
-	defer func() {
-		err := cursor.Close(context.Background())
-		if err != nil {
-			log.Printf("failed to close cursor: %v", err)
-		}
-	}()
-
-	if err != nil {
-		log.Fatalf("failed to list blogs: %v", err)
-		return status.Error(codes.Internal, "failed list blogs")
-	}
-
-	queue := make([]*blogpb.Blog, 0)
-	for cursor.Next(context.Background()) {
-		var b blogItem
-		if err := cursor.Decode(&b); err != nil {
-			log.Printf("failed to decode blog: %v", err)
-			return status.Error(codes.Internal, "failed to list blogs")
-		}
-		queue = append(queue, b.toPb())
-
-		if len(queue) >= 2 {
-			stream.Send(&blogpb.ListBlogResponse{
-				Blog: queue,
-			})
-			queue = make([]*blogpb.Blog, 0)
-		}
-	}
-
-	if len(queue) > 0 {
-		stream.Send(&blogpb.ListBlogResponse{
-			Blog: queue,
-		})
-	}
-
-	return nil
-}
-
-type blogItem struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty"`
-	AuthorId string             `bson:"author_id"`
-	Content  string             `bson:"content"`
-	Title    string             `bson:"title"`
-}
-
-func (b *blogItem) toPb() *blogpb.Blog {
-	return &blogpb.Blog{
-		Id:       b.ID.Hex(),
-		AuthorId: b.AuthorId,
-		Title:    b.Title,
-		Content:  b.Content,
-	}
-}
-
 func connectToMongo() *mongo.Client {
 	log.Println("Connecting to mongodb.")
 	credentials := options.Credential{
@@ -191,23 +39,41 @@ func connectToMongo() *mongo.Client {
 	if err != nil {
 		log.Fatalf("Failed to connect to mongodb: %v", err)
 	}
-	collection = client.Database("mydb").Collection("blog")
 	return client
 }
 
+var (
+	addr        = flag.String("addr", ":50051", "gRPC listen address")
+	gatewayAddr = flag.String("gateway-addr", ":8080", "HTTP/JSON gateway listen address")
+	webAddr     = flag.String("web-addr", ":8081", "gRPC-Web listen address")
+	corsOrigins = flag.String("cors-origins", "*", "comma separated list of origins allowed to use gRPC-Web")
+
+	listFlushInterval = flag.Duration("list-flush-interval", 100*time.Millisecond, "how long ListBlog holds a partial batch before sending it")
+	defaultDeadline   = flag.Duration("default-deadline", 5*time.Second, "deadline of unary calls which come without one, 0 means none")
+	maxDeadline       = flag.Duration("max-deadline", 30*time.Second, "longest deadline allowed for unary calls, 0 means no limit")
+	listDeadline      = flag.Duration("list-deadline", 10*time.Minute, "longest deadline allowed for ListBlog, 0 means no limit")
+)
+
 func main() {
+	flag.Parse()
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
 	mongoClient := connectToMongo()
 
-	log.Println("Listening on port :50051.")
-	lis, err := net.Listen("tcp", ":50051")
+	log.Printf("Listening on %s.", *addr)
+	lis, err := net.Listen("tcp", *addr)
 	if err != nil {
 		log.Fatalf("failed to listen %v", err)
 	}
 
-	s := grpc.NewServer()
-	blogpb.RegisterBlogServiceServer(s, &server{})
+	svc := blogsvc.New(blogsvc.Config{
+		Store:             blogstore.NewMongo(mongoClient.Database("mydb").Collection("blog")),
+		ListFlushInterval: *listFlushInterval,
+		Deadline:          deadline.Limit{Default: *defaultDeadline, Max: *maxDeadline},
+		ListDeadline:      deadline.Limit{Default: *listDeadline, Max: *listDeadline},
+	})
+	s := grpc.NewServer(svc.ServerOptions()...)
+	blogpb.RegisterBlogServiceServer(s, svc)
 
 	reflection.Register(s)
 
@@ -217,9 +83,32 @@ func main() {
 		}
 	}()
 
+	log.Printf("Serving HTTP gateway on %s.", *gatewayAddr)
+	gw, err := newGateway(context.Background(), lis.Addr().String(), *gatewayAddr)
+	if err != nil {
+		log.Fatalf("failed to create gateway: %v", err)
+	}
+	go func() {
+		if err := gw.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve gateway: %v", err)
+		}
+	}()
+
+	log.Printf("Serving gRPC-Web on %s.", *webAddr)
+	ws := web.NewServer(*webAddr, s, web.CorsConfig{AllowedOrigins: web.ParseOrigins(*corsOrigins)})
+	go func() {
+		if err := ws.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve gRPC-Web: %v", err)
+		}
+	}()
+
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, os.Interrupt)
 	<-ch
+	fmt.Println("Stoping gateway.")
+	gw.Shutdown(context.TODO())
+	fmt.Println("Stoping gRPC-Web.")
+	ws.Shutdown(context.TODO())
 	fmt.Println("Stoping server.")
 	s.Stop()
 	fmt.Println("Closing listener.")
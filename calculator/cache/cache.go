@@ -0,0 +1,124 @@
+// Package cache implements bounded LRU cache of RPC responses and gRPC
+// interceptors serving deterministic methods from it.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Config limits size and lifetime of cached entries. Zero values disable
+// corresponding limit, but at least one of MaxEntries or MaxBytes should be
+// set to keep the cache bounded.
+type Config struct {
+	MaxEntries int
+	MaxBytes   int64
+	TTL        time.Duration
+}
+
+// Stats are cache counters since creation.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Entries   int
+	Bytes     int64
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	size    int64
+	expires time.Time
+}
+
+// Cache is LRU cache safe for concurrent use. Zero value is not usable, use New.
+type Cache struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	ll      *list.List
+	items   map[string]*list.Element
+	bytes   int64
+	hits    int64
+	misses  int64
+	evicted int64
+}
+
+// New creates empty cache.
+func New(cfg Config) *Cache {
+	return &Cache{
+		cfg:   cfg,
+		now:   time.Now,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns value stored under key and marks it as recently used. Expired
+// entries are removed and reported as misses.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if ok && c.expired(el.Value.(*entry)) {
+		c.remove(el)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.ll.MoveToFront(el)
+	return el.Value.(*entry).value, true
+}
+
+// Add stores value of given size under key, evicting least recently used
+// entries if limits are exceeded. Values larger than MaxBytes are not stored.
+func (c *Cache) Add(key string, value interface{}, size int64) {
+	size += int64(len(key))
+	if c.cfg.MaxBytes > 0 && size > c.cfg.MaxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	e := &entry{key: key, value: value, size: size}
+	if c.cfg.TTL > 0 {
+		e.expires = c.now().Add(c.cfg.TTL)
+	}
+	c.items[key] = c.ll.PushFront(e)
+	c.bytes += size
+	for (c.cfg.MaxEntries > 0 && c.ll.Len() > c.cfg.MaxEntries) || (c.cfg.MaxBytes > 0 && c.bytes > c.cfg.MaxBytes) {
+		c.remove(c.ll.Back())
+		c.evicted++
+	}
+}
+
+// Stats returns snapshot of cache counters.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evicted,
+		Entries:   c.ll.Len(),
+		Bytes:     c.bytes,
+	}
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return !e.expires.IsZero() && !c.now().Before(e.expires)
+}
+
+func (c *Cache) remove(el *list.Element) {
+	e := c.ll.Remove(el).(*entry)
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
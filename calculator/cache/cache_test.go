@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestLRU(t *testing.T) {
+	c := New(Config{MaxEntries: 2})
+	c.Add("a", 1, 0)
+	c.Add("b", 2, 0)
+	c.Get("a")
+	c.Add("c", 3, 0)
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("least recently used entry was not evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("entry %q was evicted", k)
+		}
+	}
+	st := c.Stats()
+	if st.Hits != 3 || st.Misses != 1 || st.Evictions != 1 || st.Entries != 2 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	c := New(Config{MaxBytes: 10})
+	c.Add("a", 1, 5)
+	c.Add("b", 2, 5)
+	if st := c.Stats(); st.Entries != 1 || st.Bytes != 6 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	c.Add("c", 3, 100)
+	if _, ok := c.Get("c"); ok {
+		t.Errorf("entry larger than cache was stored")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New(Config{MaxEntries: 10, TTL: time.Minute})
+	c.now = func() time.Time { return now }
+	c.Add("a", 1, 0)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("entry expired too early")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("entry did not expire")
+	}
+	if st := c.Stats(); st.Entries != 0 {
+		t.Errorf("expired entry was not removed: %+v", st)
+	}
+}
+
+const method = "/test.Service/Method"
+
+var methods = Methods{method: func() proto.Message { return &wrapperspb.Int64Value{} }}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := New(Config{MaxEntries: 10})
+	intercept := UnaryServerInterceptor(c, methods)
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return wrapperspb.Int64(req.(*wrapperspb.Int64Value).Value * 2), nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	call := func(ctx context.Context, n int64) int64 {
+		res, err := intercept(ctx, wrapperspb.Int64(n), info, handler)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return res.(*wrapperspb.Int64Value).Value
+	}
+	ctx := context.Background()
+	if call(ctx, 2) != 4 || call(ctx, 2) != 4 || call(ctx, 3) != 6 {
+		t.Errorf("unexpected result")
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, expected 2", calls)
+	}
+	bypass := metadata.NewIncomingContext(ctx, metadata.Pairs(ControlKey, "no-cache"))
+	call(bypass, 2)
+	if calls != 3 {
+		t.Errorf("no-cache request was served from cache")
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	req  proto.Message
+	sent []int64
+}
+
+func (s *fakeStream) Context() context.Context { return context.Background() }
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m.(*wrapperspb.Int64Value).Value)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	c := New(Config{MaxEntries: 10})
+	intercept := StreamServerInterceptor(c, methods)
+	calls := 0
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		calls++
+		req := &wrapperspb.Int64Value{}
+		if err := ss.RecvMsg(req); err != nil {
+			return err
+		}
+		for i := int64(1); i <= req.Value; i++ {
+			if err := ss.SendMsg(wrapperspb.Int64(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}
+	for i := 0; i < 2; i++ {
+		ss := &fakeStream{req: wrapperspb.Int64(3)}
+		if err := intercept(nil, ss, info, handler); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if len(ss.sent) != 3 || ss.sent[0] != 1 || ss.sent[2] != 3 {
+			t.Errorf("unexpected messages %v", ss.sent)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, expected 1", calls)
+	}
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Metadata keys controlling caching of single request. Clients can send
+// "cache-control: no-cache" to skip lookup and "cache-control: no-store" to
+// prevent storing the response. Servers report "x-cache: hit" or "x-cache: miss"
+// in response header.
+const (
+	ControlKey = "cache-control"
+	StatusKey  = "x-cache"
+)
+
+// Methods maps full method names of deterministic RPCs to constructors of
+// their request messages. Only unary and server streaming methods are supported.
+type Methods map[string]func() proto.Message
+
+type control struct {
+	noCache, noStore bool
+}
+
+func requestControl(ctx context.Context) control {
+	var c control
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(ControlKey) {
+		for _, d := range strings.Split(v, ",") {
+			switch strings.ToLower(strings.TrimSpace(d)) {
+			case "no-cache":
+				c.noCache = true
+			case "no-store":
+				c.noStore = true
+			}
+		}
+	}
+	return c
+}
+
+// key identifies request by method and deterministic serialization of message.
+func key(method string, req proto.Message) (string, bool) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", false
+	}
+	return method + "\x00" + string(b), true
+}
+
+func setStatus(ctx context.Context, hit bool) {
+	v := "miss"
+	if hit {
+		v = "hit"
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(StatusKey, v))
+}
+
+// UnaryServerInterceptor serves unary methods listed in methods from c.
+// Only successful responses are cached.
+func UnaryServerInterceptor(c *Cache, methods Methods) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		m, ok := req.(proto.Message)
+		if _, cached := methods[info.FullMethod]; !cached || !ok {
+			return handler(ctx, req)
+		}
+		k, ok := key(info.FullMethod, m)
+		if !ok {
+			return handler(ctx, req)
+		}
+		ctl := requestControl(ctx)
+		if !ctl.noCache {
+			if v, ok := c.Get(k); ok {
+				setStatus(ctx, true)
+				return proto.Clone(v.(proto.Message)), nil
+			}
+		}
+		setStatus(ctx, false)
+		res, err := handler(ctx, req)
+		if err != nil || ctl.noStore {
+			return res, err
+		}
+		if rm, ok := res.(proto.Message); ok {
+			rm = proto.Clone(rm)
+			c.Add(k, rm, int64(proto.Size(rm)))
+		}
+		return res, nil
+	}
+}
+
+// StreamServerInterceptor serves server streaming methods listed in methods
+// from c. All messages sent by successful calls are cached and replayed in
+// the same order.
+func StreamServerInterceptor(c *Cache, methods Methods) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newReq, ok := methods[info.FullMethod]
+		if !ok || info.IsClientStream {
+			return handler(srv, ss)
+		}
+		req := newReq()
+		if err := ss.RecvMsg(req); err != nil {
+			return err
+		}
+		k, ok := key(info.FullMethod, req)
+		if !ok {
+			return handler(srv, &recordingStream{ServerStream: ss, req: req})
+		}
+		ctx := ss.Context()
+		ctl := requestControl(ctx)
+		if !ctl.noCache {
+			if v, ok := c.Get(k); ok {
+				setStatus(ctx, true)
+				for _, m := range v.([]proto.Message) {
+					if err := ss.SendMsg(m); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+		setStatus(ctx, false)
+		rs := &recordingStream{ServerStream: ss, req: req, record: !ctl.noStore}
+		if err := handler(srv, rs); err != nil {
+			return err
+		}
+		if rs.record {
+			c.Add(k, rs.sent, rs.size)
+		}
+		return nil
+	}
+}
+
+// recordingStream returns already received request to the handler and
+// records messages sent by it.
+type recordingStream struct {
+	grpc.ServerStream
+	req      proto.Message
+	received bool
+
+	record bool
+	sent   []proto.Message
+	size   int64
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	if s.received {
+		return s.ServerStream.RecvMsg(m)
+	}
+	s.received = true
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+func (s *recordingStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	if s.record {
+		pm := proto.Clone(m.(proto.Message))
+		s.sent = append(s.sent, pm)
+		s.size += int64(proto.Size(pm))
+	}
+	return nil
+}
@@ -22,12 +22,45 @@ type CalculatorClient interface {
 	Sum(ctx context.Context, in *SumRequest, opts ...grpc.CallOption) (*SumResponse, error)
 	// Server Streaming
 	PrimeNumberDecomposition(ctx context.Context, in *PrimeNumberDecompositionRequest, opts ...grpc.CallOption) (Calculator_PrimeNumberDecompositionClient, error)
-	// Client Streaming
+	// Client Streaming, this RPC throw an INVALID_ARGUMENT if no numbers are sent
 	ComputeAverage(ctx context.Context, opts ...grpc.CallOption) (Calculator_ComputeAverageClient, error)
+	// Client Streaming, this RPC throw an INVALID_ARGUMENT if no numbers are sent
+	ComputeStatistics(ctx context.Context, opts ...grpc.CallOption) (Calculator_ComputeStatisticsClient, error)
+	// Bidirectional streaming, statistics are sent back after every number
+	RunningStatistics(ctx context.Context, opts ...grpc.CallOption) (Calculator_RunningStatisticsClient, error)
 	// Bidirectional streaming
 	FindMaximum(ctx context.Context, opts ...grpc.CallOption) (Calculator_FindMaximumClient, error)
-	// this RPC throw an INVALID_ARGUMENT if number is negative
+	// this RPC throw an INVALID_ARGUMENT if number is negative and
+	// allow_complex is not set
 	SquareRoot(ctx context.Context, in *SquareRootRequest, opts ...grpc.CallOption) (*SquareRootResponse, error)
+	// this RPC throw an INVALID_ARGUMENT on division by zero or logarithm of
+	// zero and OUT_OF_RANGE if result overflows
+	ComplexCompute(ctx context.Context, in *ComplexRequest, opts ...grpc.CallOption) (*ComplexResponse, error)
+	// Unit RPCs throw an INVALID_ARGUMENT for unknown or incompatible units
+	ConvertUnits(ctx context.Context, in *ConvertUnitsRequest, opts ...grpc.CallOption) (*ConvertUnitsResponse, error)
+	UnitCompute(ctx context.Context, in *UnitComputeRequest, opts ...grpc.CallOption) (*UnitComputeResponse, error)
+	// this RPC throw an INVALID_ARGUMENT if batch has more operations than allowed
+	BatchCompute(ctx context.Context, in *BatchComputeRequest, opts ...grpc.CallOption) (*BatchComputeResponse, error)
+	// Bidirectional streaming, results are sent as soon as they are computed
+	// so they may arrive in different order than operations
+	BatchComputeStream(ctx context.Context, opts ...grpc.CallOption) (Calculator_BatchComputeStreamClient, error)
+	// Bidirectional streaming, every command is answered with the session state.
+	// Sessions are kept on the server and can be resumed by id from another
+	// stream until they expire. This RPC throw a NOT_FOUND if session to resume
+	// does not exist and RESOURCE_EXHAUSTED if there are too many sessions
+	Session(ctx context.Context, opts ...grpc.CallOption) (Calculator_SessionClient, error)
+	// Linear algebra RPCs throw an INVALID_ARGUMENT if dimensions do not match
+	// and RESOURCE_EXHAUSTED if operands are too large
+	DotProduct(ctx context.Context, in *DotProductRequest, opts ...grpc.CallOption) (*DotProductResponse, error)
+	MatrixMultiply(ctx context.Context, in *MatrixMultiplyRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	Transpose(ctx context.Context, in *TransposeRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	Determinant(ctx context.Context, in *DeterminantRequest, opts ...grpc.CallOption) (*DeterminantResponse, error)
+	// this RPC throw an INVALID_ARGUMENT if matrix is singular
+	Inverse(ctx context.Context, in *InverseRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	// this RPC throw an INVALID_ARGUMENT if matrix is singular
+	SolveLinearSystem(ctx context.Context, in *SolveLinearSystemRequest, opts ...grpc.CallOption) (*SolveLinearSystemResponse, error)
+	// this RPC throw an INVALID_ARGUMENT with ExpressionError detail if expression is invalid
+	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
 }
 
 type calculatorClient struct {
@@ -113,8 +146,73 @@ func (x *calculatorComputeAverageClient) CloseAndRecv() (*AverageResponse, error
 	return m, nil
 }
 
+func (c *calculatorClient) ComputeStatistics(ctx context.Context, opts ...grpc.CallOption) (Calculator_ComputeStatisticsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[2], "/calculator.Calculator/ComputeStatistics", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &calculatorComputeStatisticsClient{stream}
+	return x, nil
+}
+
+type Calculator_ComputeStatisticsClient interface {
+	Send(*StatisticsRequest) error
+	CloseAndRecv() (*StatisticsResponse, error)
+	grpc.ClientStream
+}
+
+type calculatorComputeStatisticsClient struct {
+	grpc.ClientStream
+}
+
+func (x *calculatorComputeStatisticsClient) Send(m *StatisticsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *calculatorComputeStatisticsClient) CloseAndRecv() (*StatisticsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(StatisticsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *calculatorClient) RunningStatistics(ctx context.Context, opts ...grpc.CallOption) (Calculator_RunningStatisticsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[3], "/calculator.Calculator/RunningStatistics", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &calculatorRunningStatisticsClient{stream}
+	return x, nil
+}
+
+type Calculator_RunningStatisticsClient interface {
+	Send(*StatisticsRequest) error
+	Recv() (*StatisticsResponse, error)
+	grpc.ClientStream
+}
+
+type calculatorRunningStatisticsClient struct {
+	grpc.ClientStream
+}
+
+func (x *calculatorRunningStatisticsClient) Send(m *StatisticsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *calculatorRunningStatisticsClient) Recv() (*StatisticsResponse, error) {
+	m := new(StatisticsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *calculatorClient) FindMaximum(ctx context.Context, opts ...grpc.CallOption) (Calculator_FindMaximumClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[2], "/calculator.Calculator/FindMaximum", opts...)
+	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[4], "/calculator.Calculator/FindMaximum", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -153,6 +251,167 @@ func (c *calculatorClient) SquareRoot(ctx context.Context, in *SquareRootRequest
 	return out, nil
 }
 
+func (c *calculatorClient) ComplexCompute(ctx context.Context, in *ComplexRequest, opts ...grpc.CallOption) (*ComplexResponse, error) {
+	out := new(ComplexResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/ComplexCompute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) ConvertUnits(ctx context.Context, in *ConvertUnitsRequest, opts ...grpc.CallOption) (*ConvertUnitsResponse, error) {
+	out := new(ConvertUnitsResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/ConvertUnits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) UnitCompute(ctx context.Context, in *UnitComputeRequest, opts ...grpc.CallOption) (*UnitComputeResponse, error) {
+	out := new(UnitComputeResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/UnitCompute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) BatchCompute(ctx context.Context, in *BatchComputeRequest, opts ...grpc.CallOption) (*BatchComputeResponse, error) {
+	out := new(BatchComputeResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/BatchCompute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) BatchComputeStream(ctx context.Context, opts ...grpc.CallOption) (Calculator_BatchComputeStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[5], "/calculator.Calculator/BatchComputeStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &calculatorBatchComputeStreamClient{stream}
+	return x, nil
+}
+
+type Calculator_BatchComputeStreamClient interface {
+	Send(*Operation) error
+	Recv() (*OperationResult, error)
+	grpc.ClientStream
+}
+
+type calculatorBatchComputeStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *calculatorBatchComputeStreamClient) Send(m *Operation) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *calculatorBatchComputeStreamClient) Recv() (*OperationResult, error) {
+	m := new(OperationResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *calculatorClient) Session(ctx context.Context, opts ...grpc.CallOption) (Calculator_SessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[6], "/calculator.Calculator/Session", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &calculatorSessionClient{stream}
+	return x, nil
+}
+
+type Calculator_SessionClient interface {
+	Send(*SessionRequest) error
+	Recv() (*SessionResponse, error)
+	grpc.ClientStream
+}
+
+type calculatorSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *calculatorSessionClient) Send(m *SessionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *calculatorSessionClient) Recv() (*SessionResponse, error) {
+	m := new(SessionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *calculatorClient) DotProduct(ctx context.Context, in *DotProductRequest, opts ...grpc.CallOption) (*DotProductResponse, error) {
+	out := new(DotProductResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/DotProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) MatrixMultiply(ctx context.Context, in *MatrixMultiplyRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/MatrixMultiply", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) Transpose(ctx context.Context, in *TransposeRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/Transpose", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) Determinant(ctx context.Context, in *DeterminantRequest, opts ...grpc.CallOption) (*DeterminantResponse, error) {
+	out := new(DeterminantResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/Determinant", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) Inverse(ctx context.Context, in *InverseRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/Inverse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) SolveLinearSystem(ctx context.Context, in *SolveLinearSystemRequest, opts ...grpc.CallOption) (*SolveLinearSystemResponse, error) {
+	out := new(SolveLinearSystemResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/SolveLinearSystem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error) {
+	out := new(EvaluateResponse)
+	err := c.cc.Invoke(ctx, "/calculator.Calculator/Evaluate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CalculatorServer is the server API for Calculator service.
 // All implementations must embed UnimplementedCalculatorServer
 // for forward compatibility
@@ -161,12 +420,45 @@ type CalculatorServer interface {
 	Sum(context.Context, *SumRequest) (*SumResponse, error)
 	// Server Streaming
 	PrimeNumberDecomposition(*PrimeNumberDecompositionRequest, Calculator_PrimeNumberDecompositionServer) error
-	// Client Streaming
+	// Client Streaming, this RPC throw an INVALID_ARGUMENT if no numbers are sent
 	ComputeAverage(Calculator_ComputeAverageServer) error
+	// Client Streaming, this RPC throw an INVALID_ARGUMENT if no numbers are sent
+	ComputeStatistics(Calculator_ComputeStatisticsServer) error
+	// Bidirectional streaming, statistics are sent back after every number
+	RunningStatistics(Calculator_RunningStatisticsServer) error
 	// Bidirectional streaming
 	FindMaximum(Calculator_FindMaximumServer) error
-	// this RPC throw an INVALID_ARGUMENT if number is negative
+	// this RPC throw an INVALID_ARGUMENT if number is negative and
+	// allow_complex is not set
 	SquareRoot(context.Context, *SquareRootRequest) (*SquareRootResponse, error)
+	// this RPC throw an INVALID_ARGUMENT on division by zero or logarithm of
+	// zero and OUT_OF_RANGE if result overflows
+	ComplexCompute(context.Context, *ComplexRequest) (*ComplexResponse, error)
+	// Unit RPCs throw an INVALID_ARGUMENT for unknown or incompatible units
+	ConvertUnits(context.Context, *ConvertUnitsRequest) (*ConvertUnitsResponse, error)
+	UnitCompute(context.Context, *UnitComputeRequest) (*UnitComputeResponse, error)
+	// this RPC throw an INVALID_ARGUMENT if batch has more operations than allowed
+	BatchCompute(context.Context, *BatchComputeRequest) (*BatchComputeResponse, error)
+	// Bidirectional streaming, results are sent as soon as they are computed
+	// so they may arrive in different order than operations
+	BatchComputeStream(Calculator_BatchComputeStreamServer) error
+	// Bidirectional streaming, every command is answered with the session state.
+	// Sessions are kept on the server and can be resumed by id from another
+	// stream until they expire. This RPC throw a NOT_FOUND if session to resume
+	// does not exist and RESOURCE_EXHAUSTED if there are too many sessions
+	Session(Calculator_SessionServer) error
+	// Linear algebra RPCs throw an INVALID_ARGUMENT if dimensions do not match
+	// and RESOURCE_EXHAUSTED if operands are too large
+	DotProduct(context.Context, *DotProductRequest) (*DotProductResponse, error)
+	MatrixMultiply(context.Context, *MatrixMultiplyRequest) (*MatrixResponse, error)
+	Transpose(context.Context, *TransposeRequest) (*MatrixResponse, error)
+	Determinant(context.Context, *DeterminantRequest) (*DeterminantResponse, error)
+	// this RPC throw an INVALID_ARGUMENT if matrix is singular
+	Inverse(context.Context, *InverseRequest) (*MatrixResponse, error)
+	// this RPC throw an INVALID_ARGUMENT if matrix is singular
+	SolveLinearSystem(context.Context, *SolveLinearSystemRequest) (*SolveLinearSystemResponse, error)
+	// this RPC throw an INVALID_ARGUMENT with ExpressionError detail if expression is invalid
+	Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error)
 	mustEmbedUnimplementedCalculatorServer()
 }
 
@@ -183,12 +475,57 @@ func (UnimplementedCalculatorServer) PrimeNumberDecomposition(*PrimeNumberDecomp
 func (UnimplementedCalculatorServer) ComputeAverage(Calculator_ComputeAverageServer) error {
 	return status.Errorf(codes.Unimplemented, "method ComputeAverage not implemented")
 }
+func (UnimplementedCalculatorServer) ComputeStatistics(Calculator_ComputeStatisticsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ComputeStatistics not implemented")
+}
+func (UnimplementedCalculatorServer) RunningStatistics(Calculator_RunningStatisticsServer) error {
+	return status.Errorf(codes.Unimplemented, "method RunningStatistics not implemented")
+}
 func (UnimplementedCalculatorServer) FindMaximum(Calculator_FindMaximumServer) error {
 	return status.Errorf(codes.Unimplemented, "method FindMaximum not implemented")
 }
 func (UnimplementedCalculatorServer) SquareRoot(context.Context, *SquareRootRequest) (*SquareRootResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SquareRoot not implemented")
 }
+func (UnimplementedCalculatorServer) ComplexCompute(context.Context, *ComplexRequest) (*ComplexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ComplexCompute not implemented")
+}
+func (UnimplementedCalculatorServer) ConvertUnits(context.Context, *ConvertUnitsRequest) (*ConvertUnitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConvertUnits not implemented")
+}
+func (UnimplementedCalculatorServer) UnitCompute(context.Context, *UnitComputeRequest) (*UnitComputeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnitCompute not implemented")
+}
+func (UnimplementedCalculatorServer) BatchCompute(context.Context, *BatchComputeRequest) (*BatchComputeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCompute not implemented")
+}
+func (UnimplementedCalculatorServer) BatchComputeStream(Calculator_BatchComputeStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchComputeStream not implemented")
+}
+func (UnimplementedCalculatorServer) Session(Calculator_SessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
+func (UnimplementedCalculatorServer) DotProduct(context.Context, *DotProductRequest) (*DotProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DotProduct not implemented")
+}
+func (UnimplementedCalculatorServer) MatrixMultiply(context.Context, *MatrixMultiplyRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MatrixMultiply not implemented")
+}
+func (UnimplementedCalculatorServer) Transpose(context.Context, *TransposeRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transpose not implemented")
+}
+func (UnimplementedCalculatorServer) Determinant(context.Context, *DeterminantRequest) (*DeterminantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Determinant not implemented")
+}
+func (UnimplementedCalculatorServer) Inverse(context.Context, *InverseRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inverse not implemented")
+}
+func (UnimplementedCalculatorServer) SolveLinearSystem(context.Context, *SolveLinearSystemRequest) (*SolveLinearSystemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SolveLinearSystem not implemented")
+}
+func (UnimplementedCalculatorServer) Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
 func (UnimplementedCalculatorServer) mustEmbedUnimplementedCalculatorServer() {}
 
 // UnsafeCalculatorServer may be embedded to opt out of forward compatibility for this service.
@@ -267,6 +604,58 @@ func (x *calculatorComputeAverageServer) Recv() (*AverageRequest, error) {
 	return m, nil
 }
 
+func _Calculator_ComputeStatistics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CalculatorServer).ComputeStatistics(&calculatorComputeStatisticsServer{stream})
+}
+
+type Calculator_ComputeStatisticsServer interface {
+	SendAndClose(*StatisticsResponse) error
+	Recv() (*StatisticsRequest, error)
+	grpc.ServerStream
+}
+
+type calculatorComputeStatisticsServer struct {
+	grpc.ServerStream
+}
+
+func (x *calculatorComputeStatisticsServer) SendAndClose(m *StatisticsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *calculatorComputeStatisticsServer) Recv() (*StatisticsRequest, error) {
+	m := new(StatisticsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Calculator_RunningStatistics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CalculatorServer).RunningStatistics(&calculatorRunningStatisticsServer{stream})
+}
+
+type Calculator_RunningStatisticsServer interface {
+	Send(*StatisticsResponse) error
+	Recv() (*StatisticsRequest, error)
+	grpc.ServerStream
+}
+
+type calculatorRunningStatisticsServer struct {
+	grpc.ServerStream
+}
+
+func (x *calculatorRunningStatisticsServer) Send(m *StatisticsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *calculatorRunningStatisticsServer) Recv() (*StatisticsRequest, error) {
+	m := new(StatisticsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Calculator_FindMaximum_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(CalculatorServer).FindMaximum(&calculatorFindMaximumServer{stream})
 }
@@ -311,6 +700,256 @@ func _Calculator_SquareRoot_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Calculator_ComplexCompute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComplexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).ComplexCompute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/ComplexCompute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).ComplexCompute(ctx, req.(*ComplexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_ConvertUnits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertUnitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).ConvertUnits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/ConvertUnits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).ConvertUnits(ctx, req.(*ConvertUnitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_UnitCompute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnitComputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).UnitCompute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/UnitCompute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).UnitCompute(ctx, req.(*UnitComputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_BatchCompute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchComputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).BatchCompute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/BatchCompute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).BatchCompute(ctx, req.(*BatchComputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_BatchComputeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CalculatorServer).BatchComputeStream(&calculatorBatchComputeStreamServer{stream})
+}
+
+type Calculator_BatchComputeStreamServer interface {
+	Send(*OperationResult) error
+	Recv() (*Operation, error)
+	grpc.ServerStream
+}
+
+type calculatorBatchComputeStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *calculatorBatchComputeStreamServer) Send(m *OperationResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *calculatorBatchComputeStreamServer) Recv() (*Operation, error) {
+	m := new(Operation)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Calculator_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CalculatorServer).Session(&calculatorSessionServer{stream})
+}
+
+type Calculator_SessionServer interface {
+	Send(*SessionResponse) error
+	Recv() (*SessionRequest, error)
+	grpc.ServerStream
+}
+
+type calculatorSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *calculatorSessionServer) Send(m *SessionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *calculatorSessionServer) Recv() (*SessionRequest, error) {
+	m := new(SessionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Calculator_DotProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DotProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).DotProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/DotProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).DotProduct(ctx, req.(*DotProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_MatrixMultiply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatrixMultiplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).MatrixMultiply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/MatrixMultiply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).MatrixMultiply(ctx, req.(*MatrixMultiplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_Transpose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).Transpose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/Transpose",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).Transpose(ctx, req.(*TransposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_Determinant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeterminantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).Determinant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/Determinant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).Determinant(ctx, req.(*DeterminantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_Inverse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InverseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).Inverse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/Inverse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).Inverse(ctx, req.(*InverseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_SolveLinearSystem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolveLinearSystemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).SolveLinearSystem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/SolveLinearSystem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).SolveLinearSystem(ctx, req.(*SolveLinearSystemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.Calculator/Evaluate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).Evaluate(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Calculator_ServiceDesc is the grpc.ServiceDesc for Calculator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -326,6 +965,50 @@ var Calculator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SquareRoot",
 			Handler:    _Calculator_SquareRoot_Handler,
 		},
+		{
+			MethodName: "ComplexCompute",
+			Handler:    _Calculator_ComplexCompute_Handler,
+		},
+		{
+			MethodName: "ConvertUnits",
+			Handler:    _Calculator_ConvertUnits_Handler,
+		},
+		{
+			MethodName: "UnitCompute",
+			Handler:    _Calculator_UnitCompute_Handler,
+		},
+		{
+			MethodName: "BatchCompute",
+			Handler:    _Calculator_BatchCompute_Handler,
+		},
+		{
+			MethodName: "DotProduct",
+			Handler:    _Calculator_DotProduct_Handler,
+		},
+		{
+			MethodName: "MatrixMultiply",
+			Handler:    _Calculator_MatrixMultiply_Handler,
+		},
+		{
+			MethodName: "Transpose",
+			Handler:    _Calculator_Transpose_Handler,
+		},
+		{
+			MethodName: "Determinant",
+			Handler:    _Calculator_Determinant_Handler,
+		},
+		{
+			MethodName: "Inverse",
+			Handler:    _Calculator_Inverse_Handler,
+		},
+		{
+			MethodName: "SolveLinearSystem",
+			Handler:    _Calculator_SolveLinearSystem_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _Calculator_Evaluate_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -338,12 +1021,35 @@ var Calculator_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Calculator_ComputeAverage_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ComputeStatistics",
+			Handler:       _Calculator_ComputeStatistics_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "RunningStatistics",
+			Handler:       _Calculator_RunningStatistics_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "FindMaximum",
 			Handler:       _Calculator_FindMaximum_Handler,
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "BatchComputeStream",
+			Handler:       _Calculator_BatchComputeStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Session",
+			Handler:       _Calculator_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "calculator/calculatorpb/calculator.proto",
 }
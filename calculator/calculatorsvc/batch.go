@@ -0,0 +1,129 @@
+package calculatorsvc
+
+import (
+	"context"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/streaming"
+	"io"
+	"runtime"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchOperations limits number of operations in single BatchCompute call.
+const maxBatchOperations = 10000
+
+// batchWorkers is number of operations computed concurrently within one call.
+var batchWorkers = runtime.NumCPU()
+
+// compute executes single operation, failure is reported in result so it
+// does not affect other operations of the batch.
+func (s *Server) compute(ctx context.Context, op *calculatorpb.Operation) *calculatorpb.OperationResult {
+	res := &calculatorpb.OperationResult{Id: op.Id}
+
+	var err error
+	switch o := op.Operation.(type) {
+	case *calculatorpb.Operation_Sum:
+		var r *calculatorpb.SumResponse
+		if r, err = s.Sum(ctx, o.Sum); err == nil {
+			res.Result = &calculatorpb.OperationResult_Sum{Sum: r}
+		}
+	case *calculatorpb.Operation_SquareRoot:
+		var r *calculatorpb.SquareRootResponse
+		if r, err = s.SquareRoot(ctx, o.SquareRoot); err == nil {
+			res.Result = &calculatorpb.OperationResult_SquareRoot{SquareRoot: r}
+		}
+	case *calculatorpb.Operation_Factorize:
+		r := &calculatorpb.FactorizeResult{}
+		err = factorize(ctx, o.Factorize, func(f *calculatorpb.PrimeNumberDecompositionResponse) error {
+			r.Factors = append(r.Factors, f)
+			return nil
+		})
+		if err == nil {
+			res.Result = &calculatorpb.OperationResult_Factorize{Factorize: r}
+		}
+	case *calculatorpb.Operation_Evaluate:
+		var r *calculatorpb.EvaluateResponse
+		if r, err = s.Evaluate(ctx, o.Evaluate); err == nil {
+			res.Result = &calculatorpb.OperationResult_Evaluate{Evaluate: r}
+		}
+	default:
+		err = status.Error(codes.InvalidArgument, "operation is not set")
+	}
+
+	if err != nil {
+		res.Result = &calculatorpb.OperationResult_Error{Error: status.Convert(err).Proto()}
+	}
+	return res
+}
+
+func (s *Server) BatchCompute(ctx context.Context, req *calculatorpb.BatchComputeRequest) (*calculatorpb.BatchComputeResponse, error) {
+	if len(req.Operations) > maxBatchOperations {
+		return nil, status.Errorf(codes.InvalidArgument, "batch has %d operations, at most %d are allowed", len(req.Operations), maxBatchOperations)
+	}
+
+	results := make([]*calculatorpb.OperationResult, len(req.Operations))
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < batchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = s.compute(ctx, req.Operations[i])
+			}
+		}()
+	}
+
+	for i := range req.Operations {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return &calculatorpb.BatchComputeResponse{Results: results}, nil
+}
+
+func (s *Server) BatchComputeStream(stream calculatorpb.Calculator_BatchComputeStreamServer) error {
+	// results wait in sender buffer while client is slow, once it is full
+	// workers block and no new operations are started
+	sender := streaming.NewSender(stream, batchWorkers)
+	ctx := sender.Context()
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, batchWorkers)
+	)
+	for {
+		op, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			wg.Wait()
+			sender.Close()
+			return err
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return sender.Close()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			sender.Send(s.compute(ctx, op))
+		}()
+	}
+
+	wg.Wait()
+	return sender.Close()
+}
@@ -0,0 +1,31 @@
+package calculatorsvc
+
+import (
+	"grpc-udemy/calculator/cache"
+	"grpc-udemy/calculator/calculatorpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// cachedMethods are deterministic methods whose responses depend only on request.
+var cachedMethods = cache.Methods{
+	"/calculator.Calculator/PrimeNumberDecomposition": func() proto.Message { return &calculatorpb.PrimeNumberDecompositionRequest{} },
+	"/calculator.Calculator/SquareRoot":               func() proto.Message { return &calculatorpb.SquareRootRequest{} },
+	"/calculator.Calculator/Evaluate":                 func() proto.Message { return &calculatorpb.EvaluateRequest{} },
+	"/calculator.Calculator/Determinant":              func() proto.Message { return &calculatorpb.DeterminantRequest{} },
+	"/calculator.Calculator/Inverse":                  func() proto.Message { return &calculatorpb.InverseRequest{} },
+	"/calculator.Calculator/SolveLinearSystem":        func() proto.Message { return &calculatorpb.SolveLinearSystemRequest{} },
+}
+
+// cacheOptions returns server options installing response cache for
+// cachedMethods.
+func (s *Server) cacheOptions() []grpc.ServerOption {
+	if s.cache == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(cache.UnaryServerInterceptor(s.cache, cachedMethods)),
+		grpc.ChainStreamInterceptor(cache.StreamServerInterceptor(s.cache, cachedMethods)),
+	}
+}
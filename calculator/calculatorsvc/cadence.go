@@ -0,0 +1,28 @@
+package calculatorsvc
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	defaultMaximumInterval = time.Second
+	maxMaximumInterval     = 10 * time.Second
+)
+
+// maximumInterval returns delay before emitted maximum, nil means current.
+func maximumInterval(d *durationpb.Duration, current time.Duration) (time.Duration, error) {
+	if d == nil {
+		return current, nil
+	}
+	if err := d.CheckValid(); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid interval: %v", err)
+	}
+	if v := d.AsDuration(); v < 0 || v > maxMaximumInterval {
+		return 0, status.Errorf(codes.InvalidArgument, "interval must be between 0 and %v, got %v", maxMaximumInterval, v)
+	}
+	return d.AsDuration(), nil
+}
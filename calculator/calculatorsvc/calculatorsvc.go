@@ -0,0 +1,301 @@
+// Package calculatorsvc implements Calculator service: arithmetic on big
+// integers, statistics, expressions, linear algebra, complex numbers, units
+// and stateful sessions.
+package calculatorsvc
+
+import (
+	"context"
+	"grpc-udemy/calculator/cache"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/expr"
+	"grpc-udemy/calculator/factor"
+	"grpc-udemy/calculator/stats"
+	"grpc-udemy/deadline"
+	"grpc-udemy/streaming"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config configures Server, zero values select defaults.
+type Config struct {
+	// Cache serves responses of deterministic methods, nil disables caching.
+	Cache *cache.Cache
+	// SessionTTL is how long sessions are kept after their last stream ends.
+	SessionTTL time.Duration
+	// Deadline bounds deadlines of unary calls.
+	Deadline deadline.Limit
+	// Now and Sleep replace the real clock, mainly in tests.
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Server implements calculatorpb.CalculatorServer.
+type Server struct {
+	calculatorpb.UnimplementedCalculatorServer
+	sessions *sessionStore
+	cache    *cache.Cache
+	deadline deadline.Limit
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// New creates Server configured by cfg.
+func New(cfg Config) *Server {
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = defaultSessionTTL
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Sleep == nil {
+		cfg.Sleep = streaming.Sleep
+	}
+	return &Server{
+		sessions: newSessionStore(cfg.SessionTTL, cfg.Now),
+		cache:    cfg.Cache,
+		deadline: cfg.Deadline,
+		sleep:    cfg.Sleep,
+	}
+}
+
+// ServerOptions returns server options installing interceptors of s:
+// deadlines are enforced before cache lookups.
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	return append(s.deadlineOptions(), s.cacheOptions()...)
+}
+
+func (s *Server) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	x, err := parseInteger(req.X, req.BigX)
+	if err != nil {
+		return nil, err
+	}
+	y, err := parseInteger(req.Y, req.BigY)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, bigSum, err := formatInteger(new(big.Int).Add(x, y), req.BigX != "" || req.BigY != "")
+	if err != nil {
+		return nil, err
+	}
+	res := &calculatorpb.SumResponse{
+		Sum:    sum,
+		BigSum: bigSum,
+	}
+	return res, nil
+}
+
+// PrimeNumberDecomposition streams prime factors with their multiplicities.
+// Factorization stops as soon as client cancels the call.
+func (s *Server) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.Calculator_PrimeNumberDecompositionServer) error {
+	return factorize(stream.Context(), req, func(res *calculatorpb.PrimeNumberDecompositionResponse) error {
+		return streaming.Send(stream, res)
+	})
+}
+
+// factorize calls emit for every distinct prime factor of requested number.
+func factorize(ctx context.Context, req *calculatorpb.PrimeNumberDecompositionRequest, emit func(*calculatorpb.PrimeNumberDecompositionResponse) error) error {
+	n, err := parseInteger(req.Number, req.BigNumber)
+	if err != nil {
+		return err
+	}
+	if n.Sign() <= 0 {
+		return status.Errorf(codes.InvalidArgument, "number %v is not positive", n)
+	}
+	bigMode := req.BigNumber != ""
+
+	err = factor.Factorize(ctx, n, func(f factor.Factor) error {
+		number, bigNumber, err := formatInteger(f.Prime, bigMode)
+		if err != nil {
+			return err
+		}
+		return emit(&calculatorpb.PrimeNumberDecompositionResponse{
+			Number:       number,
+			BigNumber:    bigNumber,
+			Multiplicity: int32(f.Multiplicity),
+		})
+	})
+	return streaming.Error(err)
+}
+
+func (s *Server) ComputeAverage(stream calculatorpb.Calculator_ComputeAverageServer) error {
+	sum := 0.0
+	count := 0
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if count == 0 {
+				return status.Error(codes.InvalidArgument, "no numbers to average")
+			}
+			return stream.SendAndClose(&calculatorpb.AverageResponse{
+				Number: sum / float64(count),
+			})
+		}
+		if err != nil {
+			return err
+		}
+		sum += req.Number
+		count++
+	}
+}
+
+func (s *Server) ComputeStatistics(stream calculatorpb.Calculator_ComputeStatisticsServer) error {
+	st := stats.New()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			res, err := statisticsResponse(st)
+			if err != nil {
+				return err
+			}
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+		if err := st.Add(req.Number); err != nil {
+			return status.Errorf(codes.InvalidArgument, "number %v: %v", req.Number, err)
+		}
+	}
+}
+
+func (s *Server) RunningStatistics(stream calculatorpb.Calculator_RunningStatisticsServer) error {
+	st := stats.New()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if _, err := st.Summary(); err != nil {
+				return status.Error(codes.InvalidArgument, "no numbers to compute statistics")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := st.Add(req.Number); err != nil {
+			return status.Errorf(codes.InvalidArgument, "number %v: %v", req.Number, err)
+		}
+
+		res, err := statisticsResponse(st)
+		if err != nil {
+			return err
+		}
+		if err := streaming.Send(stream, res); err != nil {
+			return err
+		}
+	}
+}
+
+func statisticsResponse(st *stats.Stream) (*calculatorpb.StatisticsResponse, error) {
+	sum, err := st.Summary()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "no numbers to compute statistics")
+	}
+	return &calculatorpb.StatisticsResponse{
+		Count:    sum.Count,
+		Sum:      sum.Sum,
+		Min:      sum.Min,
+		Max:      sum.Max,
+		Mean:     sum.Mean,
+		Variance: sum.Variance,
+		Stddev:   sum.Stddev,
+		Median:   sum.Median,
+		P90:      sum.P90,
+		P99:      sum.P99,
+	}, nil
+}
+
+func (s *Server) FindMaximum(stream calculatorpb.Calculator_FindMaximumServer) error {
+	var max *big.Int
+	interval := defaultMaximumInterval
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		n, err := parseInteger(req.Number, req.BigNumber)
+		if err != nil {
+			return err
+		}
+		interval, err = maximumInterval(req.Interval, interval)
+		if err != nil {
+			return err
+		}
+		if max == nil || n.Cmp(max) > 0 {
+			max = n
+			if err := s.sleep(stream.Context(), interval); err != nil {
+				return err
+			}
+
+			number, bigNumber, err := formatInteger(max, req.BigNumber != "")
+			if err != nil {
+				return err
+			}
+			err = streaming.Send(stream, &calculatorpb.MaximumResponse{
+				Number:    number,
+				BigNumber: bigNumber,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) SquareRoot(ctx context.Context, req *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
+	n, err := parseInteger(req.Number, req.BigNumber)
+	if err != nil {
+		return nil, err
+	}
+	imaginary := n.Sign() < 0
+	if imaginary {
+		if !req.AllowComplex {
+			return nil, status.Errorf(codes.InvalidArgument, "number %v is negative", n)
+		}
+		// sqrt(-n) = i*sqrt(n)
+		n.Neg(n)
+	}
+
+	if req.BigNumber == "" {
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return &calculatorpb.SquareRootResponse{
+			NumberRoot: math.Sqrt(f),
+			Imaginary:  imaginary,
+		}, nil
+	}
+
+	// keep enough bits for all integer digits of the root plus fraction
+	prec := uint(n.BitLen()) + 128
+	root := new(big.Float).SetPrec(prec).SetInt(n)
+	root.Sqrt(root)
+	f, _ := root.Float64()
+
+	return &calculatorpb.SquareRootResponse{
+		NumberRoot:    f,
+		BigNumberRoot: root.Text('g', int(float64(prec)*math.Log10(2))),
+		Imaginary:     imaginary,
+	}, nil
+}
+
+func (s *Server) Evaluate(ctx context.Context, req *calculatorpb.EvaluateRequest) (*calculatorpb.EvaluateResponse, error) {
+	result, err := expr.Eval(req.Expression, req.Variables)
+	if err != nil {
+		return nil, expressionError(err)
+	}
+
+	return &calculatorpb.EvaluateResponse{
+		Result: result,
+	}, nil
+}
@@ -0,0 +1,617 @@
+package calculatorsvc_test
+
+import (
+	"context"
+	"fmt"
+	"grpc-udemy/calculator/cache"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/calculatorsvc"
+	"grpc-udemy/grpctest"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newClient(t *testing.T) (calculatorpb.CalculatorClient, *grpctest.Clock) {
+	clock := grpctest.NewClock(time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC))
+	return grpctest.Calculator(t, calculatorsvc.Config{Now: clock.Now, Sleep: clock.Sleep}), clock
+}
+
+func TestSum(t *testing.T) {
+	client, _ := newClient(t)
+	tests := []struct {
+		req     *calculatorpb.SumRequest
+		want    int64
+		wantBig string
+		code    codes.Code
+	}{
+		{req: &calculatorpb.SumRequest{X: 3, Y: 5}, want: 8},
+		{req: &calculatorpb.SumRequest{X: -3, Y: 1}, want: -2},
+		{req: &calculatorpb.SumRequest{BigX: "9223372036854775807", Y: 1}, wantBig: "9223372036854775808"},
+		{req: &calculatorpb.SumRequest{X: math.MaxInt64, Y: 1}, code: codes.OutOfRange},
+		{req: &calculatorpb.SumRequest{BigX: "12a"}, code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		res, err := client.Sum(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("Sum(%v) error = %v, want %v", tt.req, err, tt.code)
+			continue
+		}
+		if err == nil && (res.Sum != tt.want || res.BigSum != tt.wantBig) {
+			t.Errorf("Sum(%v) = %v, want %d %q", tt.req, res, tt.want, tt.wantBig)
+		}
+	}
+}
+
+func factorize(client calculatorpb.CalculatorClient, ctx context.Context, req *calculatorpb.PrimeNumberDecompositionRequest) (string, metadata.MD, error) {
+	stream, err := client.PrimeNumberDecomposition(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+	var factors []string
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		f := fmt.Sprint(res.Number)
+		if res.BigNumber != "" {
+			f = res.BigNumber
+		}
+		if res.Multiplicity > 1 {
+			f += fmt.Sprintf("^%d", res.Multiplicity)
+		}
+		factors = append(factors, f)
+	}
+	header, err := stream.Header()
+	return strings.Join(factors, " "), header, err
+}
+
+func TestPrimeNumberDecomposition(t *testing.T) {
+	client, _ := newClient(t)
+	tests := []struct {
+		req  *calculatorpb.PrimeNumberDecompositionRequest
+		want string
+		code codes.Code
+	}{
+		{req: &calculatorpb.PrimeNumberDecompositionRequest{Number: 120}, want: "2^3 3 5"},
+		{req: &calculatorpb.PrimeNumberDecompositionRequest{Number: 1}, want: ""},
+		{req: &calculatorpb.PrimeNumberDecompositionRequest{Number: 97}, want: "97"},
+		{req: &calculatorpb.PrimeNumberDecompositionRequest{BigNumber: "18446744073709551617"}, want: "274177 67280421310721"},
+		{req: &calculatorpb.PrimeNumberDecompositionRequest{Number: 0}, code: codes.InvalidArgument},
+		{req: &calculatorpb.PrimeNumberDecompositionRequest{Number: -4}, code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		got, _, err := factorize(client, context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("PrimeNumberDecomposition(%v) error = %v, want %v", tt.req, err, tt.code)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PrimeNumberDecomposition(%v) = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+}
+
+func TestPrimeNumberDecompositionCanceled(t *testing.T) {
+	client, _ := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// product of two 101-bit primes, out of reach of Pollard's rho
+	req := &calculatorpb.PrimeNumberDecompositionRequest{BigNumber: "1606938044258990275541962093111894167460966469892788384261671"}
+	start := time.Now()
+	if _, _, err := factorize(client, ctx, req); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("error = %v, want DEADLINE_EXCEEDED", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("factorization did not stop at deadline")
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := cache.New(cache.Config{MaxEntries: 10})
+	client := grpctest.Calculator(t, calculatorsvc.Config{Cache: c})
+	req := &calculatorpb.PrimeNumberDecompositionRequest{Number: 360}
+
+	for _, want := range []string{"miss", "hit"} {
+		got, header, err := factorize(client, context.Background(), req)
+		if err != nil || got != "2^3 3^2 5" {
+			t.Fatalf("PrimeNumberDecomposition = %q, %v", got, err)
+		}
+		if v := header.Get(cache.StatusKey); len(v) != 1 || v[0] != want {
+			t.Errorf("x-cache = %v, want %s", v, want)
+		}
+	}
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), cache.ControlKey, "no-cache")
+	if _, err := client.SquareRoot(ctx, &calculatorpb.SquareRootRequest{Number: 4}, grpc.Header(&header)); err != nil {
+		t.Fatalf("SquareRoot: %v", err)
+	}
+	if v := header.Get(cache.StatusKey); len(v) != 1 || v[0] != "miss" {
+		t.Errorf("no-cache x-cache = %v, want miss", v)
+	}
+	if st := c.Stats(); st.Hits != 1 || st.Entries != 2 {
+		t.Errorf("cache stats = %+v, want 1 hit and 2 entries", st)
+	}
+}
+
+func TestComputeAverage(t *testing.T) {
+	client, _ := newClient(t)
+	average := func(numbers ...float64) (float64, error) {
+		stream, err := client.ComputeAverage(context.Background())
+		if err != nil {
+			return 0, err
+		}
+		for _, n := range numbers {
+			if err := stream.Send(&calculatorpb.AverageRequest{Number: n}); err != nil {
+				return 0, err
+			}
+		}
+		res, err := stream.CloseAndRecv()
+		return res.GetNumber(), err
+	}
+	if got, err := average(1, 2, 3, 4); err != nil || got != 2.5 {
+		t.Errorf("average = %v, %v, want 2.5", got, err)
+	}
+	if _, err := average(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty average error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestComputeStatistics(t *testing.T) {
+	client, _ := newClient(t)
+	statistics := func(numbers ...float64) (*calculatorpb.StatisticsResponse, error) {
+		stream, err := client.ComputeStatistics(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range numbers {
+			if err := stream.Send(&calculatorpb.StatisticsRequest{Number: n}); err != nil {
+				break
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+	res, err := statistics(4, 1, 5, 2, 3)
+	if err != nil {
+		t.Fatalf("ComputeStatistics: %v", err)
+	}
+	if res.Count != 5 || res.Sum != 15 || res.Min != 1 || res.Max != 5 || res.Mean != 3 || res.Median != 3 {
+		t.Errorf("ComputeStatistics = %v", res)
+	}
+	if _, err := statistics(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty statistics error = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := statistics(1, math.NaN()); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("NaN statistics error = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := statistics(1e308, -1e308); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("overflowing statistics error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestRunningStatistics(t *testing.T) {
+	client, _ := newClient(t)
+	stream, err := client.RunningStatistics(context.Background())
+	if err != nil {
+		t.Fatalf("RunningStatistics: %v", err)
+	}
+	for i, n := range []float64{2, 4, 9} {
+		if err := stream.Send(&calculatorpb.StatisticsRequest{Number: n}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if res.Count != int64(i+1) || res.Max != n {
+			t.Errorf("statistics after %v = %v", n, res)
+		}
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv after CloseSend = %v, want EOF", err)
+	}
+
+	stream, err = client.RunningStatistics(context.Background())
+	if err != nil {
+		t.Fatalf("RunningStatistics: %v", err)
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty stream error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestFindMaximum(t *testing.T) {
+	client, clock := newClient(t)
+	maxima := func(reqs ...*calculatorpb.MaximumRequest) ([]string, error) {
+		stream, err := client.FindMaximum(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range reqs {
+			if err := stream.Send(req); err != nil {
+				break
+			}
+		}
+		stream.CloseSend()
+		var res []string
+		for {
+			m, err := stream.Recv()
+			if err == io.EOF {
+				return res, nil
+			}
+			if err != nil {
+				return res, err
+			}
+			if m.BigNumber != "" {
+				res = append(res, m.BigNumber)
+			} else {
+				res = append(res, fmt.Sprint(m.Number))
+			}
+		}
+	}
+
+	got, err := maxima(
+		&calculatorpb.MaximumRequest{Number: 1},
+		&calculatorpb.MaximumRequest{Number: 5},
+		&calculatorpb.MaximumRequest{Number: 3},
+		&calculatorpb.MaximumRequest{Number: 6, Interval: durationpb.New(5 * time.Second)},
+		&calculatorpb.MaximumRequest{BigNumber: "100000000000000000000"},
+	)
+	if want := "[1 5 6 100000000000000000000]"; err != nil || fmt.Sprint(got) != want {
+		t.Errorf("FindMaximum = %v, %v, want %s", got, err, want)
+	}
+	// default 1s interval for two maxima, then 5s for two
+	if got := clock.Slept(); got != 12*time.Second {
+		t.Errorf("waited %v before maxima, want 12s", got)
+	}
+
+	if _, err := maxima(&calculatorpb.MaximumRequest{Number: 1, Interval: durationpb.New(time.Minute)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("long interval error = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := maxima(&calculatorpb.MaximumRequest{BigNumber: "x"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid number error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestSquareRoot(t *testing.T) {
+	client, _ := newClient(t)
+	tests := []struct {
+		req       *calculatorpb.SquareRootRequest
+		want      float64
+		imaginary bool
+		code      codes.Code
+	}{
+		{req: &calculatorpb.SquareRootRequest{Number: 16}, want: 4},
+		{req: &calculatorpb.SquareRootRequest{Number: 2}, want: math.Sqrt2},
+		{req: &calculatorpb.SquareRootRequest{Number: -4, AllowComplex: true}, want: 2, imaginary: true},
+		{req: &calculatorpb.SquareRootRequest{BigNumber: "10000000000000000000000000000000000000000"}, want: 1e20},
+		{req: &calculatorpb.SquareRootRequest{Number: -4}, code: codes.InvalidArgument},
+		{req: &calculatorpb.SquareRootRequest{BigNumber: "four"}, code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		res, err := client.SquareRoot(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("SquareRoot(%v) error = %v, want %v", tt.req, err, tt.code)
+			continue
+		}
+		if err == nil && (res.NumberRoot != tt.want || res.Imaginary != tt.imaginary) {
+			t.Errorf("SquareRoot(%v) = %v, want %v imaginary %v", tt.req, res, tt.want, tt.imaginary)
+		}
+		if tt.req.BigNumber != "" && err == nil && !strings.HasPrefix(res.BigNumberRoot, "100000000000000000000") {
+			t.Errorf("SquareRoot(%v) big root = %q", tt.req, res.BigNumberRoot)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	client, _ := newClient(t)
+	res, err := client.Evaluate(context.Background(), &calculatorpb.EvaluateRequest{
+		Expression: "2 * (3 + x) ^ 2",
+		Variables:  map[string]float64{"x": 1},
+	})
+	if err != nil || res.Result != 32 {
+		t.Errorf("Evaluate = %v, %v, want 32", res, err)
+	}
+
+	_, err = client.Evaluate(context.Background(), &calculatorpb.EvaluateRequest{Expression: "2 * (3 +"})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("syntax error = %v, want INVALID_ARGUMENT with details", err)
+	}
+	if e, ok := st.Details()[0].(*calculatorpb.ExpressionError); !ok || e.Position != 8 {
+		t.Errorf("error detail = %v, want position 8", st.Details()[0])
+	}
+	if _, err := client.Evaluate(context.Background(), &calculatorpb.EvaluateRequest{Expression: "y + 1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown variable error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestBatchCompute(t *testing.T) {
+	client, _ := newClient(t)
+	res, err := client.BatchCompute(context.Background(), &calculatorpb.BatchComputeRequest{Operations: []*calculatorpb.Operation{
+		{Id: "sum", Operation: &calculatorpb.Operation_Sum{Sum: &calculatorpb.SumRequest{X: 1, Y: 2}}},
+		{Id: "sqrt", Operation: &calculatorpb.Operation_SquareRoot{SquareRoot: &calculatorpb.SquareRootRequest{Number: 9}}},
+		{Id: "factors", Operation: &calculatorpb.Operation_Factorize{Factorize: &calculatorpb.PrimeNumberDecompositionRequest{Number: 12}}},
+		{Id: "eval", Operation: &calculatorpb.Operation_Evaluate{Evaluate: &calculatorpb.EvaluateRequest{Expression: "2+2"}}},
+		{Id: "bad", Operation: &calculatorpb.Operation_SquareRoot{SquareRoot: &calculatorpb.SquareRootRequest{Number: -1}}},
+		{Id: "empty"},
+	}})
+	if err != nil {
+		t.Fatalf("BatchCompute: %v", err)
+	}
+	if len(res.Results) != 6 {
+		t.Fatalf("got %d results, want 6", len(res.Results))
+	}
+	r := res.Results
+	if r[0].GetSum().GetSum() != 3 || r[1].GetSquareRoot().GetNumberRoot() != 3 ||
+		len(r[2].GetFactorize().GetFactors()) != 2 || r[3].GetEvaluate().GetResult() != 4 {
+		t.Errorf("results = %v", r)
+	}
+	for _, i := range []int{4, 5} {
+		if r[i].GetError().GetCode() != int32(codes.InvalidArgument) {
+			t.Errorf("result %s = %v, want INVALID_ARGUMENT error", r[i].Id, r[i])
+		}
+	}
+
+	ops := make([]*calculatorpb.Operation, 10001)
+	for i := range ops {
+		ops[i] = &calculatorpb.Operation{}
+	}
+	if _, err := client.BatchCompute(context.Background(), &calculatorpb.BatchComputeRequest{Operations: ops}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("oversized batch error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestBatchComputeStream(t *testing.T) {
+	client, _ := newClient(t)
+	stream, err := client.BatchComputeStream(context.Background())
+	if err != nil {
+		t.Fatalf("BatchComputeStream: %v", err)
+	}
+	const n = 500
+	go func() {
+		for i := 0; i < n; i++ {
+			op := &calculatorpb.Operation{Id: fmt.Sprint(i), Operation: &calculatorpb.Operation_Sum{Sum: &calculatorpb.SumRequest{X: int64(i), Y: 1}}}
+			if err := stream.Send(op); err != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	var sums []int
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if res.Id != fmt.Sprint(res.GetSum().GetSum()-1) {
+			t.Errorf("result %v does not match its operation", res)
+		}
+		sums = append(sums, int(res.GetSum().GetSum()))
+	}
+	sort.Ints(sums)
+	if len(sums) != n || sums[0] != 1 || sums[n-1] != n {
+		t.Errorf("got %d results from %v to %v, want %d", len(sums), sums[0], sums[len(sums)-1], n)
+	}
+}
+
+// session drives Session stream one command at a time.
+type session struct {
+	t      *testing.T
+	stream calculatorpb.Calculator_SessionClient
+}
+
+func openSession(t *testing.T, client calculatorpb.CalculatorClient) *session {
+	t.Helper()
+	stream, err := client.Session(context.Background())
+	if err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	return &session{t: t, stream: stream}
+}
+
+func (s *session) do(req *calculatorpb.SessionRequest) *calculatorpb.SessionResponse {
+	s.t.Helper()
+	if err := s.stream.Send(req); err != nil {
+		s.t.Fatalf("Send: %v", err)
+	}
+	res, err := s.stream.Recv()
+	if err != nil {
+		s.t.Fatalf("Recv: %v", err)
+	}
+	return res
+}
+
+func (s *session) close() {
+	s.stream.CloseSend()
+	if _, err := s.stream.Recv(); err != io.EOF {
+		s.t.Errorf("Recv after CloseSend = %v, want EOF", err)
+	}
+}
+
+func apply(op calculatorpb.SessionOperation, v float64) *calculatorpb.SessionRequest {
+	return &calculatorpb.SessionRequest{Command: &calculatorpb.SessionRequest_Apply{Apply: &calculatorpb.ApplyCommand{
+		Operation: op,
+		Operand:   &calculatorpb.ApplyCommand_Value{Value: v},
+	}}}
+}
+
+func TestSession(t *testing.T) {
+	client, _ := newClient(t)
+	s := openSession(t, client)
+
+	res := s.do(apply(calculatorpb.SessionOperation_SESSION_SET, 5))
+	id := res.SessionId
+	if id == "" || res.Accumulator != 5 {
+		t.Fatalf("set = %v", res)
+	}
+	res = s.do(&calculatorpb.SessionRequest{Command: &calculatorpb.SessionRequest_Store{Store: &calculatorpb.StoreCommand{Name: "x"}}})
+	if res.Variables["x"] != 5 {
+		t.Errorf("store = %v", res)
+	}
+	res = s.do(&calculatorpb.SessionRequest{Command: &calculatorpb.SessionRequest_Apply{Apply: &calculatorpb.ApplyCommand{
+		Operation: calculatorpb.SessionOperation_SESSION_MULTIPLY,
+		Operand:   &calculatorpb.ApplyCommand_Variable{Variable: "x"},
+	}}})
+	if res.Accumulator != 25 {
+		t.Errorf("multiply by x = %v, want 25", res)
+	}
+	res = s.do(&calculatorpb.SessionRequest{Command: &calculatorpb.SessionRequest_Undo{Undo: &calculatorpb.UndoCommand{}}})
+	if res.Accumulator != 5 {
+		t.Errorf("undo = %v, want 5", res)
+	}
+
+	// failed commands are reported without ending the stream
+	failures := []struct {
+		req  *calculatorpb.SessionRequest
+		code codes.Code
+	}{
+		{apply(calculatorpb.SessionOperation_SESSION_DIVIDE, 0), codes.InvalidArgument},
+		{apply(calculatorpb.SessionOperation_SESSION_OPERATION_UNSPECIFIED, 1), codes.InvalidArgument},
+		{&calculatorpb.SessionRequest{Command: &calculatorpb.SessionRequest_Recall{Recall: &calculatorpb.RecallCommand{Name: "y"}}}, codes.NotFound},
+		{&calculatorpb.SessionRequest{Command: &calculatorpb.SessionRequest_Store{Store: &calculatorpb.StoreCommand{}}}, codes.InvalidArgument},
+		{apply(calculatorpb.SessionOperation_SESSION_POWER, 1e6), codes.OutOfRange},
+	}
+	for _, f := range failures {
+		res := s.do(f.req)
+		if codes.Code(res.Error.GetCode()) != f.code || res.Accumulator != 5 {
+			t.Errorf("%v = %v, want %v error and unchanged state", f.req, res, f.code)
+		}
+	}
+	s.close()
+
+	// state survives the stream
+	s = openSession(t, client)
+	res = s.do(&calculatorpb.SessionRequest{SessionId: id})
+	if res.SessionId != id || res.Accumulator != 5 || res.Variables["x"] != 5 {
+		t.Errorf("resumed session = %v", res)
+	}
+	s.close()
+}
+
+func TestSessionExpiry(t *testing.T) {
+	clock := grpctest.NewClock(time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC))
+	client := grpctest.Calculator(t, calculatorsvc.Config{Now: clock.Now, SessionTTL: time.Minute})
+
+	s := openSession(t, client)
+	id := s.do(apply(calculatorpb.SessionOperation_SESSION_SET, 1)).SessionId
+	s.close()
+
+	resume := func() error {
+		stream, err := client.Session(context.Background())
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&calculatorpb.SessionRequest{SessionId: id}); err != nil {
+			return err
+		}
+		if _, err := stream.Recv(); err != nil {
+			return err
+		}
+		// EOF arrives after the handler returned and detached the session,
+		// so its expiry starts before the clock is advanced
+		stream.CloseSend()
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	clock.Advance(30 * time.Second)
+	if err := resume(); err != nil {
+		t.Errorf("session expired before ttl: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+	if err := resume(); status.Code(err) != codes.NotFound {
+		t.Errorf("expired session error = %v, want NOT_FOUND", err)
+	}
+}
+
+func BenchmarkSum(b *testing.B) {
+	client := grpctest.Calculator(b, calculatorsvc.Config{})
+	req := &calculatorpb.SumRequest{X: 3, Y: 5}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.Sum(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimeNumberDecomposition(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			cfg := calculatorsvc.Config{}
+			if cached {
+				cfg.Cache = cache.New(cache.Config{MaxEntries: 10})
+			}
+			client := grpctest.Calculator(b, cfg)
+			// 2^61-1 times 3^5
+			req := &calculatorpb.PrimeNumberDecompositionRequest{BigNumber: "560319851238927630093"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := factorize(client, context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	client := grpctest.Calculator(b, calculatorsvc.Config{})
+	req := &calculatorpb.EvaluateRequest{
+		Expression: "sqrt(x^2 + y^2) * 2 - (x + y) / 3",
+		Variables:  map[string]float64{"x": 3, "y": 4},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Evaluate(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBatchCompute(b *testing.B) {
+	client := grpctest.Calculator(b, calculatorsvc.Config{})
+	ops := make([]*calculatorpb.Operation, 100)
+	for i := range ops {
+		ops[i] = &calculatorpb.Operation{Id: fmt.Sprint(i), Operation: &calculatorpb.Operation_Sum{Sum: &calculatorpb.SumRequest{X: int64(i), Y: 1}}}
+	}
+	req := &calculatorpb.BatchComputeRequest{Operations: ops}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.BatchCompute(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package calculatorsvc
+
+import (
+	"context"
+	"grpc-udemy/calculator/calculatorpb"
+	"math"
+	"math/cmplx"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func toComplex(c *calculatorpb.Complex) complex128 {
+	return complex(c.GetReal(), c.GetImag())
+}
+
+func (s *Server) ComplexCompute(ctx context.Context, req *calculatorpb.ComplexRequest) (*calculatorpb.ComplexResponse, error) {
+	a, b := toComplex(req.A), toComplex(req.B)
+	var res complex128
+	switch req.Operation {
+	case calculatorpb.ComplexOperation_COMPLEX_ADD:
+		res = a + b
+	case calculatorpb.ComplexOperation_COMPLEX_SUBTRACT:
+		res = a - b
+	case calculatorpb.ComplexOperation_COMPLEX_MULTIPLY:
+		res = a * b
+	case calculatorpb.ComplexOperation_COMPLEX_DIVIDE:
+		if b == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "division by zero")
+		}
+		res = a / b
+	case calculatorpb.ComplexOperation_COMPLEX_POWER:
+		if a == 0 && real(b) < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "zero raised to negative power")
+		}
+		res = cmplx.Pow(a, b)
+	case calculatorpb.ComplexOperation_COMPLEX_SQRT:
+		res = cmplx.Sqrt(a)
+	case calculatorpb.ComplexOperation_COMPLEX_EXP:
+		res = cmplx.Exp(a)
+	case calculatorpb.ComplexOperation_COMPLEX_LOG:
+		if a == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "logarithm of zero")
+		}
+		res = cmplx.Log(a)
+	case calculatorpb.ComplexOperation_COMPLEX_CONJUGATE:
+		res = cmplx.Conj(a)
+	case calculatorpb.ComplexOperation_COMPLEX_ABS:
+		res = complex(cmplx.Abs(a), 0)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported operation %v", req.Operation)
+	}
+	if cmplx.IsNaN(res) || math.IsInf(real(res), 0) || math.IsInf(imag(res), 0) {
+		return nil, status.Errorf(codes.OutOfRange, "result of %v is not finite", req.Operation)
+	}
+	return &calculatorpb.ComplexResponse{
+		Result: &calculatorpb.Complex{Real: real(res), Imag: imag(res)},
+	}, nil
+}
@@ -0,0 +1,17 @@
+package calculatorsvc
+
+import (
+	"grpc-udemy/deadline"
+
+	"google.golang.org/grpc"
+)
+
+// deadlineOptions returns server options enforcing deadlines. Streams are
+// not limited, sessions and running statistics live as long as clients want.
+func (s *Server) deadlineOptions() []grpc.ServerOption {
+	c := deadline.Config{Unary: s.deadline}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(deadline.UnaryServerInterceptor(c)),
+		grpc.ChainStreamInterceptor(deadline.StreamServerInterceptor(c)),
+	}
+}
@@ -0,0 +1,159 @@
+package calculatorsvc
+
+import (
+	"context"
+	"errors"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/linalg"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxElements limits number of values in single vector or matrix.
+	maxElements = 1 << 20
+	// maxFlops limits number of multiplications of single operation.
+	maxFlops = 1 << 30
+)
+
+func toMatrix(name string, m *calculatorpb.Matrix) (*linalg.Matrix, error) {
+	if m == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "matrix %s is required", name)
+	}
+	if int64(m.Rows)*int64(m.Cols) > maxElements {
+		return nil, status.Errorf(codes.ResourceExhausted, "matrix %s has more than %d values", name, maxElements)
+	}
+	res, err := linalg.New(int(m.Rows), int(m.Cols), m.Values)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "matrix %s: %v", name, err)
+	}
+	return res, nil
+}
+
+func toVector(name string, v *calculatorpb.Vector) ([]float64, error) {
+	if v == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "vector %s is required", name)
+	}
+	if len(v.Values) > maxElements {
+		return nil, status.Errorf(codes.ResourceExhausted, "vector %s has more than %d values", name, maxElements)
+	}
+	return v.Values, nil
+}
+
+func fromMatrix(m *linalg.Matrix) *calculatorpb.Matrix {
+	return &calculatorpb.Matrix{
+		Rows:   int32(m.Rows),
+		Cols:   int32(m.Cols),
+		Values: m.Data,
+	}
+}
+
+// checkCubic rejects square operations whose O(n^3) cost exceeds maxFlops.
+func checkCubic(m *linalg.Matrix) error {
+	n := int64(m.Rows)
+	if n*n*n > maxFlops {
+		return status.Errorf(codes.ResourceExhausted, "%dx%d matrix is too large for this operation", m.Rows, m.Cols)
+	}
+	return nil
+}
+
+// linalgError maps linalg errors to status errors.
+func linalgError(err error) error {
+	if errors.Is(err, linalg.ErrDimension) || errors.Is(err, linalg.ErrSingular) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Errorf(codes.Internal, "internal error %v", err)
+}
+
+func (s *Server) DotProduct(ctx context.Context, req *calculatorpb.DotProductRequest) (*calculatorpb.DotProductResponse, error) {
+	a, err := toVector("a", req.A)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toVector("b", req.B)
+	if err != nil {
+		return nil, err
+	}
+	res, err := linalg.Dot(a, b)
+	if err != nil {
+		return nil, linalgError(err)
+	}
+	return &calculatorpb.DotProductResponse{Result: res}, nil
+}
+
+func (s *Server) MatrixMultiply(ctx context.Context, req *calculatorpb.MatrixMultiplyRequest) (*calculatorpb.MatrixResponse, error) {
+	a, err := toMatrix("a", req.A)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toMatrix("b", req.B)
+	if err != nil {
+		return nil, err
+	}
+	if int64(a.Rows)*int64(b.Cols) > maxElements || int64(a.Rows)*int64(a.Cols)*int64(b.Cols) > maxFlops {
+		return nil, status.Errorf(codes.ResourceExhausted, "product of %dx%d and %dx%d matrices is too large", a.Rows, a.Cols, b.Rows, b.Cols)
+	}
+	res, err := linalg.Mul(a, b)
+	if err != nil {
+		return nil, linalgError(err)
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromMatrix(res)}, nil
+}
+
+func (s *Server) Transpose(ctx context.Context, req *calculatorpb.TransposeRequest) (*calculatorpb.MatrixResponse, error) {
+	m, err := toMatrix("matrix", req.Matrix)
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromMatrix(linalg.Transpose(m))}, nil
+}
+
+func (s *Server) Determinant(ctx context.Context, req *calculatorpb.DeterminantRequest) (*calculatorpb.DeterminantResponse, error) {
+	m, err := toMatrix("matrix", req.Matrix)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCubic(m); err != nil {
+		return nil, err
+	}
+	det, err := linalg.Det(m)
+	if err != nil {
+		return nil, linalgError(err)
+	}
+	return &calculatorpb.DeterminantResponse{Determinant: det}, nil
+}
+
+func (s *Server) Inverse(ctx context.Context, req *calculatorpb.InverseRequest) (*calculatorpb.MatrixResponse, error) {
+	m, err := toMatrix("matrix", req.Matrix)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCubic(m); err != nil {
+		return nil, err
+	}
+	inv, err := linalg.Inverse(m)
+	if err != nil {
+		return nil, linalgError(err)
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromMatrix(inv)}, nil
+}
+
+func (s *Server) SolveLinearSystem(ctx context.Context, req *calculatorpb.SolveLinearSystemRequest) (*calculatorpb.SolveLinearSystemResponse, error) {
+	a, err := toMatrix("a", req.A)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toVector("b", req.B)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCubic(a); err != nil {
+		return nil, err
+	}
+	x, err := linalg.Solve(a, b)
+	if err != nil {
+		return nil, linalgError(err)
+	}
+	return &calculatorpb.SolveLinearSystemResponse{X: &calculatorpb.Vector{Values: x}}, nil
+}
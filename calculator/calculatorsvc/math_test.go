@@ -0,0 +1,237 @@
+package calculatorsvc_test
+
+import (
+	"context"
+	"grpc-udemy/calculator/calculatorpb"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestComplexCompute(t *testing.T) {
+	client, _ := newClient(t)
+	c := func(re, im float64) *calculatorpb.Complex { return &calculatorpb.Complex{Real: re, Imag: im} }
+	tests := []struct {
+		op   calculatorpb.ComplexOperation
+		a, b *calculatorpb.Complex
+		want complex128
+		code codes.Code
+	}{
+		{op: calculatorpb.ComplexOperation_COMPLEX_ADD, a: c(1, 2), b: c(3, -1), want: 4 + 1i},
+		{op: calculatorpb.ComplexOperation_COMPLEX_MULTIPLY, a: c(1, 2), b: c(3, -1), want: 5 + 5i},
+		{op: calculatorpb.ComplexOperation_COMPLEX_DIVIDE, a: c(5, 5), b: c(3, -1), want: 1 + 2i},
+		{op: calculatorpb.ComplexOperation_COMPLEX_SQRT, a: c(-4, 0), want: 2i},
+		{op: calculatorpb.ComplexOperation_COMPLEX_CONJUGATE, a: c(1, 2), want: 1 - 2i},
+		{op: calculatorpb.ComplexOperation_COMPLEX_ABS, a: c(3, 4), want: 5},
+		{op: calculatorpb.ComplexOperation_COMPLEX_DIVIDE, a: c(1, 0), code: codes.InvalidArgument},
+		{op: calculatorpb.ComplexOperation_COMPLEX_LOG, code: codes.InvalidArgument},
+		{op: calculatorpb.ComplexOperation_COMPLEX_POWER, b: c(-1, 0), code: codes.InvalidArgument},
+		{op: calculatorpb.ComplexOperation_COMPLEX_EXP, a: c(1000, 0), code: codes.OutOfRange},
+		{a: c(1, 0), code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		res, err := client.ComplexCompute(context.Background(), &calculatorpb.ComplexRequest{Operation: tt.op, A: tt.a, B: tt.b})
+		if status.Code(err) != tt.code {
+			t.Errorf("%v(%v, %v) error = %v, want %v", tt.op, tt.a, tt.b, err, tt.code)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := complex(res.Result.Real, res.Result.Imag); cmplx.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%v(%v, %v) = %v, want %v", tt.op, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestConvertUnits(t *testing.T) {
+	client, _ := newClient(t)
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+		code     codes.Code
+	}{
+		{value: 36, from: "km/h", to: "m/s", want: 10},
+		{value: 1, from: "kWh", to: "J", want: 3.6e6},
+		{value: 2, from: "km", want: 2000},
+		{value: 1, from: "kg", to: "m", code: codes.InvalidArgument},
+		{value: 1, from: "parsec^x", to: "m", code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		req := &calculatorpb.ConvertUnitsRequest{Quantity: &calculatorpb.Quantity{Value: tt.value, Unit: tt.from}, Unit: tt.to}
+		res, err := client.ConvertUnits(context.Background(), req)
+		if status.Code(err) != tt.code {
+			t.Errorf("ConvertUnits(%v) error = %v, want %v", req, err, tt.code)
+			continue
+		}
+		if err == nil && math.Abs(res.Quantity.Value-tt.want) > 1e-9*tt.want {
+			t.Errorf("ConvertUnits(%v) = %v, want %v", req, res.Quantity, tt.want)
+		}
+	}
+	if _, err := client.ConvertUnits(context.Background(), &calculatorpb.ConvertUnitsRequest{Unit: "m"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing quantity error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestUnitCompute(t *testing.T) {
+	client, _ := newClient(t)
+	q := func(v float64, unit string) *calculatorpb.Quantity {
+		return &calculatorpb.Quantity{Value: v, Unit: unit}
+	}
+	tests := []struct {
+		req  *calculatorpb.UnitComputeRequest
+		want *calculatorpb.Quantity
+		code codes.Code
+	}{
+		{
+			req:  &calculatorpb.UnitComputeRequest{Operation: calculatorpb.UnitOperation_UNIT_ADD, A: q(1, "km"), B: q(500, "m")},
+			want: q(1.5, "km"),
+		},
+		{
+			req:  &calculatorpb.UnitComputeRequest{Operation: calculatorpb.UnitOperation_UNIT_MULTIPLY, A: q(2, "m"), B: q(3, "m")},
+			want: q(6, "m^2"),
+		},
+		{
+			req:  &calculatorpb.UnitComputeRequest{Operation: calculatorpb.UnitOperation_UNIT_DIVIDE, A: q(100, "km"), B: q(2, "h"), Unit: "km/h"},
+			want: q(50, "km/h"),
+		},
+		{
+			req:  &calculatorpb.UnitComputeRequest{Operation: calculatorpb.UnitOperation_UNIT_SUBTRACT, A: q(1, "kg"), B: q(1, "s")},
+			code: codes.InvalidArgument,
+		},
+		{
+			req:  &calculatorpb.UnitComputeRequest{Operation: calculatorpb.UnitOperation_UNIT_DIVIDE, A: q(1, "m"), B: q(0, "s")},
+			code: codes.InvalidArgument,
+		},
+		{
+			req:  &calculatorpb.UnitComputeRequest{A: q(1, "m"), B: q(1, "m")},
+			code: codes.InvalidArgument,
+		},
+		{
+			req:  &calculatorpb.UnitComputeRequest{Operation: calculatorpb.UnitOperation_UNIT_ADD, A: q(1, "m")},
+			code: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		res, err := client.UnitCompute(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("UnitCompute(%v) error = %v, want %v", tt.req, err, tt.code)
+			continue
+		}
+		if err == nil && (math.Abs(res.Result.Value-tt.want.Value) > 1e-9 || res.Result.Unit != tt.want.Unit) {
+			t.Errorf("UnitCompute(%v) = %v, want %v", tt.req, res.Result, tt.want)
+		}
+	}
+}
+
+func matrix(rows, cols int32, values ...float64) *calculatorpb.Matrix {
+	return &calculatorpb.Matrix{Rows: rows, Cols: cols, Values: values}
+}
+
+func vector(values ...float64) *calculatorpb.Vector {
+	return &calculatorpb.Vector{Values: values}
+}
+
+func TestLinearAlgebra(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	dot, err := client.DotProduct(ctx, &calculatorpb.DotProductRequest{A: vector(1, 2, 3), B: vector(4, 5, 6)})
+	if err != nil || dot.Result != 32 {
+		t.Errorf("DotProduct = %v, %v, want 32", dot, err)
+	}
+
+	product, err := client.MatrixMultiply(ctx, &calculatorpb.MatrixMultiplyRequest{A: matrix(2, 3, 1, 2, 3, 4, 5, 6), B: matrix(3, 1, 1, 1, 1)})
+	if want := matrix(2, 1, 6, 15); err != nil || !proto.Equal(product.Matrix, want) {
+		t.Errorf("MatrixMultiply = %v, %v, want %v", product, err, want)
+	}
+
+	transposed, err := client.Transpose(ctx, &calculatorpb.TransposeRequest{Matrix: matrix(2, 3, 1, 2, 3, 4, 5, 6)})
+	if want := matrix(3, 2, 1, 4, 2, 5, 3, 6); err != nil || !proto.Equal(transposed.Matrix, want) {
+		t.Errorf("Transpose = %v, %v, want %v", transposed, err, want)
+	}
+
+	det, err := client.Determinant(ctx, &calculatorpb.DeterminantRequest{Matrix: matrix(2, 2, 4, 7, 2, 6)})
+	if err != nil || math.Abs(det.Determinant-10) > 1e-9 {
+		t.Errorf("Determinant = %v, %v, want 10", det, err)
+	}
+
+	inverse, err := client.Inverse(ctx, &calculatorpb.InverseRequest{Matrix: matrix(2, 2, 4, 7, 2, 6)})
+	if err != nil {
+		t.Errorf("Inverse: %v", err)
+	} else {
+		for i, want := range []float64{0.6, -0.7, -0.2, 0.4} {
+			if math.Abs(inverse.Matrix.Values[i]-want) > 1e-9 {
+				t.Errorf("Inverse = %v", inverse.Matrix)
+				break
+			}
+		}
+	}
+
+	solution, err := client.SolveLinearSystem(ctx, &calculatorpb.SolveLinearSystemRequest{A: matrix(2, 2, 2, 1, 1, 3), B: vector(3, 5)})
+	if err != nil || len(solution.X.Values) != 2 || math.Abs(solution.X.Values[0]-0.8) > 1e-9 || math.Abs(solution.X.Values[1]-1.4) > 1e-9 {
+		t.Errorf("SolveLinearSystem = %v, %v, want [0.8 1.4]", solution, err)
+	}
+}
+
+func TestLinearAlgebraErrors(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+	singular := matrix(2, 2, 1, 2, 2, 4)
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"dot length mismatch", func() error {
+			_, err := client.DotProduct(ctx, &calculatorpb.DotProductRequest{A: vector(1, 2), B: vector(1)})
+			return err
+		}, codes.InvalidArgument},
+		{"dot missing vector", func() error {
+			_, err := client.DotProduct(ctx, &calculatorpb.DotProductRequest{A: vector(1)})
+			return err
+		}, codes.InvalidArgument},
+		{"multiply dimension mismatch", func() error {
+			_, err := client.MatrixMultiply(ctx, &calculatorpb.MatrixMultiplyRequest{A: matrix(2, 2, 1, 2, 3, 4), B: matrix(3, 1, 1, 1, 1)})
+			return err
+		}, codes.InvalidArgument},
+		{"values do not match shape", func() error {
+			_, err := client.Transpose(ctx, &calculatorpb.TransposeRequest{Matrix: matrix(2, 2, 1, 2, 3)})
+			return err
+		}, codes.InvalidArgument},
+		{"missing matrix", func() error {
+			_, err := client.Transpose(ctx, &calculatorpb.TransposeRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"too many elements", func() error {
+			_, err := client.Transpose(ctx, &calculatorpb.TransposeRequest{Matrix: matrix(1<<11, 1<<10)})
+			return err
+		}, codes.ResourceExhausted},
+		{"determinant of non-square", func() error {
+			_, err := client.Determinant(ctx, &calculatorpb.DeterminantRequest{Matrix: matrix(1, 2, 1, 2)})
+			return err
+		}, codes.InvalidArgument},
+		{"inverse of singular", func() error {
+			_, err := client.Inverse(ctx, &calculatorpb.InverseRequest{Matrix: singular})
+			return err
+		}, codes.InvalidArgument},
+		{"solve singular", func() error {
+			_, err := client.SolveLinearSystem(ctx, &calculatorpb.SolveLinearSystemRequest{A: singular, B: vector(1, 2)})
+			return err
+		}, codes.InvalidArgument},
+		{"solve length mismatch", func() error {
+			_, err := client.SolveLinearSystem(ctx, &calculatorpb.SolveLinearSystemRequest{A: matrix(2, 2, 2, 1, 1, 3), B: vector(1)})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); status.Code(err) != tt.want {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package calculatorsvc
+
+import (
+	"errors"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/expr"
+	"math/big"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// parseInteger returns value of integer field, bigValue takes precedence over
+// value when set. It fails with INVALID_ARGUMENT for malformed bigValue.
+func parseInteger(value int64, bigValue string) (*big.Int, error) {
+	if bigValue == "" {
+		return big.NewInt(value), nil
+	}
+	n, ok := new(big.Int).SetString(bigValue, 10)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "%q is not a valid integer", bigValue)
+	}
+	return n, nil
+}
+
+// formatInteger splits n into int64 and big representation. Big representation
+// is returned only in big mode, outside of big mode result has to fit into int64
+// otherwise OUT_OF_RANGE is returned.
+func formatInteger(n *big.Int, bigMode bool) (int64, string, error) {
+	if !bigMode {
+		if !n.IsInt64() {
+			return 0, "", status.Errorf(codes.OutOfRange, "result %v overflows int64, use big integer fields", n)
+		}
+		return n.Int64(), "", nil
+	}
+	if n.IsInt64() {
+		return n.Int64(), n.String(), nil
+	}
+	return 0, n.String(), nil
+}
+
+// expressionError converts expression error into INVALID_ARGUMENT status with
+// ExpressionError detail pointing to position of the error.
+func expressionError(err error) error {
+	var e *expr.Error
+	if !errors.As(err, &e) {
+		return status.Errorf(codes.Internal, "failed to evaluate expression: %v", err)
+	}
+
+	st := status.New(codes.InvalidArgument, e.Error())
+	detailed, derr := st.WithDetails(&calculatorpb.ExpressionError{
+		Position: int32(e.Pos),
+		Message:  e.Msg,
+	})
+	if derr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
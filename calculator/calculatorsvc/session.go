@@ -0,0 +1,235 @@
+package calculatorsvc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/streaming"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSessionTTL   = 10 * time.Minute
+	maxSessions         = 10000
+	maxSessionVariables = 1000
+	maxUndo             = 100
+)
+
+// sessionState is immutable, variables are copied on every change so
+// snapshots kept for undo can share them.
+type sessionState struct {
+	accumulator float64
+	variables   map[string]float64
+}
+
+type session struct {
+	id string
+
+	mu      sync.Mutex
+	state   sessionState
+	history []sessionState
+
+	// guarded by sessionStore.mu
+	streams  int
+	lastUsed time.Time
+}
+
+// sessionStore keeps sessions until ttl elapses after their last stream ends.
+type sessionStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func newSessionStore(ttl time.Duration, now func() time.Time) *sessionStore {
+	return &sessionStore{ttl: ttl, now: now, sessions: make(map[string]*session)}
+}
+
+// attach returns session with given id, or new session if id is empty, and
+// marks it as used by a stream until detach is called.
+func (st *sessionStore) attach(id string) (*session, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.sweep()
+	if id != "" {
+		sess, ok := st.sessions[id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "session %q does not exist or expired", id)
+		}
+		sess.streams++
+		return sess, nil
+	}
+	if len(st.sessions) >= maxSessions {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many sessions")
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate session id: %v", err)
+	}
+	sess := &session{id: hex.EncodeToString(b), streams: 1}
+	st.sessions[sess.id] = sess
+	return sess, nil
+}
+
+func (st *sessionStore) detach(sess *session) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess.streams--
+	sess.lastUsed = st.now()
+}
+
+// sweep removes expired sessions, st.mu has to be held.
+func (st *sessionStore) sweep() {
+	now := st.now()
+	for id, sess := range st.sessions {
+		if sess.streams == 0 && now.Sub(sess.lastUsed) > st.ttl {
+			delete(st.sessions, id)
+		}
+	}
+}
+
+// execute runs command of req and returns resulting state. Failed commands
+// leave the state unchanged and report error in response.
+func (sess *session) execute(req *calculatorpb.SessionRequest) *calculatorpb.SessionResponse {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	var err error
+	next := sess.state
+	switch c := req.Command.(type) {
+	case nil:
+	case *calculatorpb.SessionRequest_Store:
+		next, err = sess.state.store(c.Store.Name)
+	case *calculatorpb.SessionRequest_Recall:
+		next.accumulator, err = sess.state.variable(c.Recall.Name)
+	case *calculatorpb.SessionRequest_Apply:
+		next.accumulator, err = sess.state.apply(c.Apply)
+	case *calculatorpb.SessionRequest_Undo:
+		if len(sess.history) == 0 {
+			err = status.Error(codes.FailedPrecondition, "nothing to undo")
+			break
+		}
+		next = sess.history[len(sess.history)-1]
+		sess.history = sess.history[:len(sess.history)-1]
+	}
+
+	res := &calculatorpb.SessionResponse{SessionId: sess.id}
+	if err != nil {
+		res.Error = status.Convert(err).Proto()
+	} else if req.Command != nil {
+		if _, undo := req.Command.(*calculatorpb.SessionRequest_Undo); !undo {
+			if len(sess.history) == maxUndo {
+				sess.history = append(sess.history[:0], sess.history[1:]...)
+			}
+			sess.history = append(sess.history, sess.state)
+		}
+		sess.state = next
+	}
+
+	res.Accumulator = sess.state.accumulator
+	res.Variables = make(map[string]float64, len(sess.state.variables))
+	for k, v := range sess.state.variables {
+		res.Variables[k] = v
+	}
+	return res
+}
+
+func (st sessionState) store(name string) (sessionState, error) {
+	if name == "" {
+		return st, status.Error(codes.InvalidArgument, "variable name is required")
+	}
+	if _, ok := st.variables[name]; !ok && len(st.variables) >= maxSessionVariables {
+		return st, status.Errorf(codes.ResourceExhausted, "session has more than %d variables", maxSessionVariables)
+	}
+	vars := make(map[string]float64, len(st.variables)+1)
+	for k, v := range st.variables {
+		vars[k] = v
+	}
+	vars[name] = st.accumulator
+	return sessionState{accumulator: st.accumulator, variables: vars}, nil
+}
+
+func (st sessionState) variable(name string) (float64, error) {
+	v, ok := st.variables[name]
+	if !ok {
+		return 0, status.Errorf(codes.NotFound, "variable %q is not set", name)
+	}
+	return v, nil
+}
+
+func (st sessionState) apply(c *calculatorpb.ApplyCommand) (float64, error) {
+	var operand float64
+	switch o := c.Operand.(type) {
+	case *calculatorpb.ApplyCommand_Value:
+		operand = o.Value
+	case *calculatorpb.ApplyCommand_Variable:
+		v, err := st.variable(o.Variable)
+		if err != nil {
+			return 0, err
+		}
+		operand = v
+	default:
+		return 0, status.Error(codes.InvalidArgument, "operand is not set")
+	}
+
+	acc := st.accumulator
+	switch c.Operation {
+	case calculatorpb.SessionOperation_SESSION_SET:
+		acc = operand
+	case calculatorpb.SessionOperation_SESSION_ADD:
+		acc += operand
+	case calculatorpb.SessionOperation_SESSION_SUBTRACT:
+		acc -= operand
+	case calculatorpb.SessionOperation_SESSION_MULTIPLY:
+		acc *= operand
+	case calculatorpb.SessionOperation_SESSION_DIVIDE:
+		if operand == 0 {
+			return 0, status.Error(codes.InvalidArgument, "division by zero")
+		}
+		acc /= operand
+	case calculatorpb.SessionOperation_SESSION_POWER:
+		acc = math.Pow(acc, operand)
+	default:
+		return 0, status.Errorf(codes.InvalidArgument, "unsupported operation %v", c.Operation)
+	}
+	if math.IsNaN(acc) || math.IsInf(acc, 0) {
+		return 0, status.Error(codes.OutOfRange, "result is not finite")
+	}
+	return acc, nil
+}
+
+func (s *Server) Session(stream calculatorpb.Calculator_SessionServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sess, err := s.sessions.attach(req.SessionId)
+	if err != nil {
+		return err
+	}
+	defer s.sessions.detach(sess)
+
+	for {
+		if err := streaming.Send(stream, sess.execute(req)); err != nil {
+			return err
+		}
+		req, err = stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
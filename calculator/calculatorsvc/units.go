@@ -0,0 +1,91 @@
+package calculatorsvc
+
+import (
+	"context"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/units"
+	"math"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func toQuantity(name string, q *calculatorpb.Quantity) (units.Quantity, error) {
+	if q == nil {
+		return units.Quantity{}, status.Errorf(codes.InvalidArgument, "quantity %s is required", name)
+	}
+	res, err := units.New(q.Value, q.Unit)
+	if err != nil {
+		return units.Quantity{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return res, nil
+}
+
+// fromQuantity expresses q in unit, empty unit means SI base units.
+func fromQuantity(q units.Quantity, unit string) (*calculatorpb.Quantity, error) {
+	if unit == "" {
+		unit = q.Dim.String()
+	}
+	v, err := q.In(unit)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, status.Errorf(codes.OutOfRange, "result is not finite")
+	}
+	return &calculatorpb.Quantity{Value: v, Unit: unit}, nil
+}
+
+func (s *Server) ConvertUnits(ctx context.Context, req *calculatorpb.ConvertUnitsRequest) (*calculatorpb.ConvertUnitsResponse, error) {
+	q, err := toQuantity("quantity", req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	res, err := fromQuantity(q, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.ConvertUnitsResponse{Quantity: res}, nil
+}
+
+func (s *Server) UnitCompute(ctx context.Context, req *calculatorpb.UnitComputeRequest) (*calculatorpb.UnitComputeResponse, error) {
+	a, err := toQuantity("a", req.A)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toQuantity("b", req.B)
+	if err != nil {
+		return nil, err
+	}
+	unit := req.Unit
+	var res units.Quantity
+	switch req.Operation {
+	case calculatorpb.UnitOperation_UNIT_ADD:
+		res, err = units.Add(a, b)
+		if unit == "" {
+			unit = req.A.Unit
+		}
+	case calculatorpb.UnitOperation_UNIT_SUBTRACT:
+		res, err = units.Sub(a, b)
+		if unit == "" {
+			unit = req.A.Unit
+		}
+	case calculatorpb.UnitOperation_UNIT_MULTIPLY:
+		res = units.Mul(a, b)
+	case calculatorpb.UnitOperation_UNIT_DIVIDE:
+		if b.Value == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "division by zero")
+		}
+		res = units.Div(a, b)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported operation %v", req.Operation)
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	q, err := fromQuantity(res, unit)
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.UnitComputeResponse{Result: q}, nil
+}
@@ -0,0 +1,156 @@
+package expr
+
+import "math"
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(map[string]float64) (float64, error) {
+	return n.value, nil
+}
+
+type varNode struct {
+	name string
+	pos  int
+}
+
+func (n *varNode) eval(vars map[string]float64) (float64, error) {
+	if v, ok := vars[n.name]; ok {
+		return v, nil
+	}
+	if v, ok := constants[n.name]; ok {
+		return v, nil
+	}
+	return 0, errorf(n.pos, "undefined variable %q", n.name)
+}
+
+type negNode struct {
+	operand node
+}
+
+func (n *negNode) eval(vars map[string]float64) (float64, error) {
+	v, err := n.operand.eval(vars)
+	return -v, err
+}
+
+type binaryNode struct {
+	op          byte
+	pos         int
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]float64) (float64, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, errorf(n.pos, "division by zero")
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return 0, errorf(n.pos, "modulo by zero")
+		}
+		return math.Mod(l, r), nil
+	case '^':
+		v := math.Pow(l, r)
+		if math.IsNaN(v) {
+			return 0, errorf(n.pos, "%v ^ %v is not a real number", l, r)
+		}
+		return v, nil
+	}
+	return 0, errorf(n.pos, "unknown operator %q", n.op)
+}
+
+type function struct {
+	minArgs, maxArgs int // maxArgs -1 means variadic
+	call             func(args []float64) float64
+}
+
+func unary(f func(float64) float64) function {
+	return function{minArgs: 1, maxArgs: 1, call: func(args []float64) float64 { return f(args[0]) }}
+}
+
+var functions = map[string]function{
+	"sqrt":  unary(math.Sqrt),
+	"cbrt":  unary(math.Cbrt),
+	"abs":   unary(math.Abs),
+	"exp":   unary(math.Exp),
+	"log":   unary(math.Log),
+	"log2":  unary(math.Log2),
+	"log10": unary(math.Log10),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"pow": {minArgs: 2, maxArgs: 2, call: func(args []float64) float64 {
+		return math.Pow(args[0], args[1])
+	}},
+	"min": {minArgs: 1, maxArgs: -1, call: func(args []float64) float64 {
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Min(v, a)
+		}
+		return v
+	}},
+	"max": {minArgs: 1, maxArgs: -1, call: func(args []float64) float64 {
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Max(v, a)
+		}
+		return v
+	}},
+}
+
+type callNode struct {
+	name string
+	pos  int
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+
+	v := n.fn.call(args)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errorf(n.pos, "%s(%v) is outside of function domain", n.name, args)
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+// Package expr parses and evaluates arithmetic expressions.
+//
+// Supported are numbers, named variables, operators + - * / % ^, parentheses
+// and functions (sqrt, log, sin, ...). Operator ^ is right associative and binds
+// tighter than unary minus, so -2^2 is -4.
+package expr
+
+import (
+	"fmt"
+	"math"
+)
+
+// Error is returned for invalid expressions and failed evaluations.
+type Error struct {
+	// Pos is zero based byte offset in expression where the error was detected.
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos int, format string, a ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+// Expr is parsed expression which can be evaluated multiple times.
+type Expr struct {
+	root node
+}
+
+// MaxLength is the length of the longest expression Parse accepts.
+const MaxLength = 64 << 10
+
+// Parse parses expression s.
+func Parse(s string) (*Expr, error) {
+	if len(s) > MaxLength {
+		return nil, errorf(MaxLength, "expression is longer than %d bytes", MaxLength)
+	}
+	p := &parser{lex: newLexer(s)}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, errorf(p.tok.pos, "unexpected %s", p.tok)
+	}
+	return &Expr{root: root}, nil
+}
+
+// Eval evaluates expression with variables bound to vars.
+// Constants pi and e are available unless shadowed by vars.
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errorf(0, "result %v is not a finite number", v)
+	}
+	return v, nil
+}
+
+// Eval parses and evaluates expression s.
+func Eval(s string, vars map[string]float64) (float64, error) {
+	e, err := Parse(s)
+	if err != nil {
+		return 0, err
+	}
+	return e.Eval(vars)
+}
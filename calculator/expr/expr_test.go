@@ -0,0 +1,130 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"x": 3, "rate": 0.5, "e": 10}
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 / 4", 2.5},
+		{"10 % 4", 2},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"2 ^ -1", 0.5},
+		{"--x", 3},
+		{"x * rate", 1.5},
+		{"e", 10},
+		{"pi", math.Pi},
+		{"sqrt(16) + abs(-2)", 6},
+		{"log(exp(2))", 2},
+		{"max(1, x, 2) - min(4, 5)", -1},
+		{"pow(2, 10)", 1024},
+		{"1.5e3 + .5", 1500.5},
+		{"sin(0) + cos(0)", 1},
+	}
+	for _, tt := range tests {
+		got, err := Eval(tt.expr, vars)
+		if err != nil {
+			t.Errorf("Eval(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"", 0},
+		{"1 +", 3},
+		{"1 + * 2", 4},
+		{"(1 + 2", 6},
+		{"1 + 2)", 5},
+		{"2 $ 3", 2},
+		{"1 / (x - x)", 2},
+		{"5 % 0", 2},
+		{"y + 1", 0},
+		{"1 + foo(2)", 4},
+		{"1 + sqrt(-1)", 4},
+		{"log(0)", 0},
+		{"pow(2)", 0},
+		{"max(1,", 6},
+		{"1e", 0},
+		{"(-8) ^ 0.5", 5},
+	}
+	for _, tt := range tests {
+		_, err := Eval(tt.expr, map[string]float64{"x": 1})
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("Eval(%q) = %v, want *Error", tt.expr, err)
+			continue
+		}
+		if e.Pos != tt.pos {
+			t.Errorf("Eval(%q) error at position %d, want %d: %v", tt.expr, e.Pos, tt.pos, e)
+		}
+	}
+}
+
+func TestParseOnceEvalMany(t *testing.T) {
+	e, err := Parse("x * x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{1, 2, 3} {
+		got, err := e.Eval(map[string]float64{"x": x})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != x*x {
+			t.Errorf("got %v, want %v", got, x*x)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "1" + strings.Repeat(")", n)
+	}
+	if got, err := Eval(nested(maxDepth-1), nil); err != nil || got != 1 {
+		t.Errorf("Eval of %d nested parentheses = %v, %v, want 1", maxDepth-1, got, err)
+	}
+	if got, err := Eval(strings.Repeat("-", maxDepth-2)+"1", nil); err != nil || got != 1 {
+		t.Errorf("Eval of %d unary minuses = %v, %v, want 1", maxDepth-2, got, err)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		pos  int
+	}{
+		{"nested parentheses", strings.Repeat("(", 10000) + "1", maxDepth},
+		{"unary minus", strings.Repeat("-", 10000) + "1", maxDepth},
+		{"nested calls", strings.Repeat("abs(", 10000) + "1", 4 * maxDepth},
+		{"too long", strings.Repeat("(", 3<<20) + "1", MaxLength},
+		{"long sum", strings.Repeat("1+", MaxLength/2) + "1", MaxLength},
+	}
+	for _, tt := range tests {
+		_, err := Eval(tt.expr, nil)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s: Eval = %v, want *Error", tt.name, err)
+			continue
+		}
+		if e.Pos != tt.pos {
+			t.Errorf("%s: error at position %d, want %d: %v", tt.name, e.Pos, tt.pos, e)
+		}
+	}
+}
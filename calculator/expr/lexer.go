@@ -0,0 +1,125 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string
+	num  float64
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type lexer struct {
+	s   string
+	pos int
+}
+
+func newLexer(s string) *lexer {
+	return &lexer{s: s}
+}
+
+func isIdentRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.s) && (l.s[l.pos] == ' ' || l.s[l.pos] == '\t' || l.s[l.pos] == '\n') {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.s) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.s[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, pos: start, text: "("}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, pos: start, text: ")"}, nil
+	case c == ',':
+		l.pos++
+		return token{kind: tokComma, pos: start, text: ","}, nil
+	case c == '+' || c == '-' || c == '*' || c == '/' || c == '%' || c == '^':
+		l.pos++
+		return token{kind: tokOperator, pos: start, text: string(c)}, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		return l.number()
+	}
+
+	r, size := utf8.DecodeRuneInString(l.s[l.pos:])
+	if !isIdentRune(r, true) {
+		return token{}, errorf(start, "unexpected character %q", r)
+	}
+	for l.pos < len(l.s) {
+		r, size = utf8.DecodeRuneInString(l.s[l.pos:])
+		if !isIdentRune(r, false) {
+			break
+		}
+		l.pos += size
+	}
+	return token{kind: tokIdent, pos: start, text: l.s[start:l.pos]}, nil
+}
+
+func (l *lexer) digits() int {
+	n := 0
+	for l.pos < len(l.s) && l.s[l.pos] >= '0' && l.s[l.pos] <= '9' {
+		l.pos++
+		n++
+	}
+	return n
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	n := l.digits()
+	if l.pos < len(l.s) && l.s[l.pos] == '.' {
+		l.pos++
+		n += l.digits()
+	}
+	if n == 0 {
+		return token{}, errorf(start, "invalid number %q", l.s[start:l.pos])
+	}
+	if l.pos < len(l.s) && (l.s[l.pos] == 'e' || l.s[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.s) && (l.s[l.pos] == '+' || l.s[l.pos] == '-') {
+			l.pos++
+		}
+		if l.digits() == 0 {
+			return token{}, errorf(start, "invalid number %q", l.s[start:l.pos])
+		}
+	}
+
+	text := l.s[start:l.pos]
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, errorf(start, "invalid number %q", text)
+	}
+	return token{kind: tokNumber, pos: start, text: text, num: v}, nil
+}
@@ -0,0 +1,184 @@
+package expr
+
+// Grammar:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("+" | "-") unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | ident | ident "(" [ expr { "," expr } ] ")" | "(" expr ")"
+type parser struct {
+	lex *lexer
+	tok token
+	// depth counts nested unary operators, parentheses and calls
+	depth int
+}
+
+// maxDepth limits nesting of expressions, so parsing and evaluating them
+// cannot overflow the stack.
+const maxDepth = 256
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) isOperator(ops string) bool {
+	if p.tok.kind != tokOperator {
+		return false
+	}
+	for _, op := range ops {
+		if p.tok.text == string(op) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseExpr() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+-") {
+		op := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text[0], pos: op.pos, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*/%") {
+		op := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text[0], pos: op.pos, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	// every nested unary, parenthesized expression or call argument passes here
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, errorf(p.tok.pos, "expression is nested deeper than %d levels", maxDepth)
+	}
+	if p.isOperator("+-") {
+		op := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op.text == "+" {
+			return operand, nil
+		}
+		return &negNode{operand: operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator("^") {
+		return base, nil
+	}
+	op := p.tok
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	exp, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: '^', pos: op.pos, left: base, right: exp}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		return &numberNode{value: tok.num}, p.next()
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, errorf(p.tok.pos, "expected \")\" to close \"(\" at position %d, got %s", tok.pos, p.tok)
+		}
+		return n, p.next()
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokLParen {
+			return &varNode{name: tok.text, pos: tok.pos}, nil
+		}
+		return p.parseCall(tok)
+	}
+	return nil, errorf(tok.pos, "unexpected %s", tok)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, errorf(name.pos, "unknown function %q", name.text)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	args := make([]node, 0)
+	if p.tok.kind != tokRParen {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.tok.kind != tokComma {
+				break
+			}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.tok.kind != tokRParen {
+		return nil, errorf(p.tok.pos, "expected \",\" or \")\" in call of %s, got %s", name.text, p.tok)
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, errorf(name.pos, "function %s called with %d arguments", name.text, len(args))
+	}
+	return &callNode{name: name.text, pos: name.pos, fn: fn, args: args}, p.next()
+}
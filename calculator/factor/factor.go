@@ -0,0 +1,136 @@
+// Package factor implements prime factorization of 64-bit and arbitrary
+// precision integers.
+//
+// Small factors are found by trial division, remaining cofactor is tested for
+// primality with Miller-Rabin and split with Pollard's rho (Brent's variant).
+// Long running factorization can be stopped by cancelling the context.
+package factor
+
+import (
+	"context"
+	"math/big"
+	"sort"
+)
+
+// trialLimit is upper bound of primes tried by trial division.
+const trialLimit = 1 << 12
+
+var smallPrimes = sieve(trialLimit)
+
+// Factor is prime factor with its multiplicity.
+type Factor struct {
+	Prime        *big.Int
+	Multiplicity int
+}
+
+func sieve(limit int) []uint64 {
+	composite := make([]bool, limit+1)
+	primes := make([]uint64, 0)
+	for i := 2; i <= limit; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, uint64(i))
+		for j := i * i; j <= limit; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// Factorize calls emit for every distinct prime factor of n in ascending order.
+// Numbers smaller than 2 have no factors. It returns ctx error when ctx is done
+// before factorization finishes, or error returned by emit.
+func Factorize(ctx context.Context, n *big.Int, emit func(Factor) error) error {
+	n = new(big.Int).Set(n)
+	one := big.NewInt(1)
+	if n.Cmp(one) <= 0 {
+		return nil
+	}
+
+	// trial division finds factors in ascending order so they can be emitted immediately
+	p, q, r := new(big.Int), new(big.Int), new(big.Int)
+	for _, sp := range smallPrimes {
+		p.SetUint64(sp)
+		if q.Mul(p, p).Cmp(n) > 0 {
+			break
+		}
+		count := 0
+		for {
+			if q.QuoRem(n, p, r); r.Sign() != 0 {
+				break
+			}
+			n.Set(q)
+			count++
+		}
+		if count > 0 {
+			if err := emit(Factor{Prime: new(big.Int).Set(p), Multiplicity: count}); err != nil {
+				return err
+			}
+		}
+	}
+	if n.Cmp(one) == 0 {
+		return nil
+	}
+
+	// remaining factors are all bigger than trial division primes
+	primes := make([]*big.Int, 0)
+	if err := split(ctx, n, &primes); err != nil {
+		return err
+	}
+	sort.Slice(primes, func(i, j int) bool { return primes[i].Cmp(primes[j]) < 0 })
+
+	for i := 0; i < len(primes); {
+		j := i + 1
+		for j < len(primes) && primes[j].Cmp(primes[i]) == 0 {
+			j++
+		}
+		if err := emit(Factor{Prime: primes[i], Multiplicity: j - i}); err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
+
+// split appends prime factors of n without small factors to primes.
+func split(ctx context.Context, n *big.Int, primes *[]*big.Int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n.IsUint64() {
+		return split64(ctx, n.Uint64(), primes)
+	}
+	if n.ProbablyPrime(20) {
+		*primes = append(*primes, n)
+		return nil
+	}
+
+	d, err := rhoBig(ctx, n)
+	if err != nil {
+		return err
+	}
+	if err := split(ctx, d, primes); err != nil {
+		return err
+	}
+	return split(ctx, new(big.Int).Quo(n, d), primes)
+}
+
+func split64(ctx context.Context, n uint64, primes *[]*big.Int) error {
+	if n == 1 {
+		return nil
+	}
+	if IsPrime64(n) {
+		*primes = append(*primes, new(big.Int).SetUint64(n))
+		return nil
+	}
+
+	d, err := rho64(ctx, n)
+	if err != nil {
+		return err
+	}
+	if err := split64(ctx, d, primes); err != nil {
+		return err
+	}
+	return split64(ctx, n/d, primes)
+}
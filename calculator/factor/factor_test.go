@@ -0,0 +1,138 @@
+package factor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func factorize(t *testing.T, n string) []string {
+	t.Helper()
+
+	v, ok := new(big.Int).SetString(n, 10)
+	if !ok {
+		t.Fatalf("invalid number %s", n)
+	}
+	res := make([]string, 0)
+	product := big.NewInt(1)
+	err := Factorize(context.Background(), v, func(f Factor) error {
+		res = append(res, f.Prime.String()+"^"+big.NewInt(int64(f.Multiplicity)).String())
+		product.Mul(product, new(big.Int).Exp(f.Prime, big.NewInt(int64(f.Multiplicity)), nil))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to factorize %s: %v", n, err)
+	}
+	if v.Cmp(big.NewInt(1)) > 0 && product.Cmp(v) != 0 {
+		t.Fatalf("product of factors of %s is %v", n, product)
+	}
+	return res
+}
+
+func TestFactorize(t *testing.T) {
+	tests := []struct {
+		n    string
+		want []string
+	}{
+		{"1", []string{}},
+		{"2", []string{"2^1"}},
+		{"120", []string{"2^3", "3^1", "5^1"}},
+		{"2147483647", []string{"2147483647^1"}},
+		{"600851475143", []string{"71^1", "839^1", "1471^1", "6857^1"}},
+		{"999999866000004473", []string{"999999929^1", "999999937^1"}},
+		{"18446744073709551557", []string{"18446744073709551557^1"}},
+		{"18446744073709551615", []string{"3^1", "5^1", "17^1", "257^1", "641^1", "65537^1", "6700417^1"}},
+		{"18446744073709551617", []string{"274177^1", "67280421310721^1"}},
+		{"100000000000000000000", []string{"2^20", "5^20"}},
+	}
+	for _, tt := range tests {
+		got := factorize(t, tt.n)
+		if len(got) != len(tt.want) {
+			t.Errorf("factors of %s = %v, want %v", tt.n, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("factors of %s = %v, want %v", tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestIsPrime64(t *testing.T) {
+	primes := []uint64{2, 3, 97, 7919, 2147483647, 999999937, 18446744073709551557}
+	for _, p := range primes {
+		if !IsPrime64(p) {
+			t.Errorf("%d is prime", p)
+		}
+	}
+	composites := []uint64{0, 1, 4, 561, 3215031751, 999999866000004473, 18446744073709551615}
+	for _, c := range composites {
+		if IsPrime64(c) {
+			t.Errorf("%d is not prime", c)
+		}
+	}
+}
+
+func TestFactorizeCancel(t *testing.T) {
+	// product of two 101-bit primes, out of reach of Pollard's rho
+	n, _ := new(big.Int).SetString("1267650600228229401496703205653", 10)
+	m, _ := new(big.Int).SetString("1267650600228229401496703205707", 10)
+	n.Mul(n, m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Factorize(ctx, n, func(Factor) error { return nil })
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("factorization stopped %v after cancel", elapsed)
+	}
+}
+
+// gapContext records the longest time between calls of Err before ctx is
+// done.
+type gapContext struct {
+	context.Context
+	last   time.Time
+	maxGap time.Duration
+}
+
+func (c *gapContext) Err() error {
+	err := c.Context.Err()
+	if err == nil {
+		now := time.Now()
+		if gap := now.Sub(c.last); gap > c.maxGap {
+			c.maxGap = gap
+		}
+		c.last = now
+	}
+	return err
+}
+
+func TestRhoBigChecksContext(t *testing.T) {
+	n, _ := new(big.Int).SetString("1267650600228229401496703205653", 10)
+	m, _ := new(big.Int).SetString("1267650600228229401496703205707", 10)
+	n.Mul(n, m)
+
+	// rounds started by the deadline advance y millions of times, so ctx
+	// must be checked within them, not only between batches of gcds
+	parent, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	ctx := &gapContext{Context: parent, last: time.Now()}
+	if _, err := rhoBig(ctx, n); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	deadline, _ := parent.Deadline()
+	if late := time.Since(deadline); late > 20*time.Millisecond {
+		t.Errorf("rhoBig returned %v after the deadline", late)
+	}
+	if ctx.maxGap > 20*time.Millisecond {
+		t.Errorf("ctx was not checked for %v", ctx.maxGap)
+	}
+}
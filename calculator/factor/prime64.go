@@ -0,0 +1,137 @@
+package factor
+
+import (
+	"context"
+	"math/bits"
+)
+
+// checkEvery is number of rho iterations between context checks.
+const checkEvery = 1 << 10
+
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+func powMod(a, e, m uint64) uint64 {
+	res := uint64(1)
+	a %= m
+	for e > 0 {
+		if e&1 == 1 {
+			res = mulMod(res, a, m)
+		}
+		a = mulMod(a, a, m)
+		e >>= 1
+	}
+	return res
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// IsPrime64 reports whether n is prime. It uses Miller-Rabin test with bases
+// which make it deterministic for all 64-bit integers.
+func IsPrime64(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	bases := []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+	for _, p := range bases {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+
+	d, s := n-1, 0
+	for d%2 == 0 {
+		d /= 2
+		s++
+	}
+	for _, a := range bases {
+		x := powMod(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		composite := true
+		for i := 1; i < s; i++ {
+			x = mulMod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
+
+// rho64 returns non-trivial divisor of composite n using Pollard's rho
+// with Brent's cycle detection, trying different polynomials until it succeeds.
+func rho64(ctx context.Context, n uint64) (uint64, error) {
+	if n%2 == 0 {
+		return 2, nil
+	}
+
+	const m = 128
+	for c := uint64(1); ; c++ {
+		f := func(x uint64) uint64 {
+			v := mulMod(x, x, n) + c
+			if v < c || v >= n {
+				v -= n
+			}
+			return v
+		}
+
+		y, r, q, g := uint64(2), uint64(1), uint64(1), uint64(1)
+		var x, ys uint64
+		iterations := 0
+		for g == 1 {
+			x = y
+			for i := uint64(0); i < r; i++ {
+				y = f(y)
+			}
+			for k := uint64(0); k < r && g == 1; k += m {
+				ys = y
+				for i := uint64(0); i < m && i < r-k; i++ {
+					y = f(y)
+					diff := x - y
+					if x < y {
+						diff = y - x
+					}
+					q = mulMod(q, diff, n)
+				}
+				g = gcd(q, n)
+
+				if iterations++; iterations%checkEvery == 0 {
+					if err := ctx.Err(); err != nil {
+						return 0, err
+					}
+				}
+			}
+			r *= 2
+		}
+
+		if g == n {
+			// batch overshot, backtrack one step at a time
+			for {
+				ys = f(ys)
+				diff := x - ys
+				if x < ys {
+					diff = ys - x
+				}
+				if g = gcd(diff, n); g > 1 {
+					break
+				}
+			}
+		}
+		if g != n {
+			return g, nil
+		}
+	}
+}
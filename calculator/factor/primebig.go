@@ -0,0 +1,71 @@
+package factor
+
+import (
+	"context"
+	"math/big"
+)
+
+// rhoBig returns non-trivial divisor of composite n using Pollard's rho
+// with Brent's cycle detection on arbitrary precision integers.
+func rhoBig(ctx context.Context, n *big.Int) (*big.Int, error) {
+	two := big.NewInt(2)
+	if new(big.Int).Mod(n, two).Sign() == 0 {
+		return two, nil
+	}
+
+	const m = 128
+	one := big.NewInt(1)
+	for c := int64(1); ; c++ {
+		bc := big.NewInt(c)
+		f := func(x *big.Int) {
+			x.Mul(x, x)
+			x.Add(x, bc)
+			x.Mod(x, n)
+		}
+
+		y, r, q, g := big.NewInt(2), 1, big.NewInt(1), big.NewInt(1)
+		x, ys, diff := new(big.Int), new(big.Int), new(big.Int)
+		for g.Cmp(one) == 0 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				// r doubles every round, so long advances check ctx too
+				if i%m == 0 {
+					if err := ctx.Err(); err != nil {
+						return nil, err
+					}
+				}
+				f(y)
+			}
+			for k := 0; k < r && g.Cmp(one) == 0; k += m {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				ys.Set(y)
+				for i := 0; i < m && i < r-k; i++ {
+					f(y)
+					diff.Sub(x, y)
+					diff.Abs(diff)
+					q.Mul(q, diff)
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+			r *= 2
+		}
+
+		if g.Cmp(n) == 0 {
+			// batch overshot, backtrack one step at a time
+			for {
+				f(ys)
+				diff.Sub(x, ys)
+				diff.Abs(diff)
+				if g.GCD(nil, nil, diff, n); g.Cmp(one) > 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(n) != 0 {
+			return g, nil
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package linalg implements basic dense linear algebra on float64 values.
+package linalg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrDimension is returned when operand dimensions do not fit the operation.
+	ErrDimension = errors.New("dimension mismatch")
+	// ErrSingular is returned when matrix has no inverse.
+	ErrSingular = errors.New("matrix is singular")
+)
+
+// Matrix is dense matrix with values stored in row-major order.
+type Matrix struct {
+	Rows, Cols int
+	Data       []float64
+}
+
+// New creates matrix of given dimensions from row-major values.
+func New(rows, cols int, data []float64) (*Matrix, error) {
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("%w: matrix dimensions %dx%d must be positive", ErrDimension, rows, cols)
+	}
+	if len(data) != rows*cols {
+		return nil, fmt.Errorf("%w: %dx%d matrix requires %d values, got %d", ErrDimension, rows, cols, rows*cols, len(data))
+	}
+	return &Matrix{Rows: rows, Cols: cols, Data: data}, nil
+}
+
+// Zeros creates matrix of given dimensions filled with zeros.
+func Zeros(rows, cols int) *Matrix {
+	return &Matrix{Rows: rows, Cols: cols, Data: make([]float64, rows*cols)}
+}
+
+// Identity creates n x n identity matrix.
+func Identity(n int) *Matrix {
+	m := Zeros(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+// At returns value at row i and column j.
+func (m *Matrix) At(i, j int) float64 {
+	return m.Data[i*m.Cols+j]
+}
+
+// Set sets value at row i and column j.
+func (m *Matrix) Set(i, j int, v float64) {
+	m.Data[i*m.Cols+j] = v
+}
+
+func (m *Matrix) clone() *Matrix {
+	data := make([]float64, len(m.Data))
+	copy(data, m.Data)
+	return &Matrix{Rows: m.Rows, Cols: m.Cols, Data: data}
+}
+
+func (m *Matrix) swapRows(i, j int) {
+	for k := 0; k < m.Cols; k++ {
+		a, b := i*m.Cols+k, j*m.Cols+k
+		m.Data[a], m.Data[b] = m.Data[b], m.Data[a]
+	}
+}
+
+// Dot returns dot product of vectors a and b.
+func Dot(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: vectors of length %d and %d", ErrDimension, len(a), len(b))
+	}
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum, nil
+}
+
+// Mul returns matrix product a * b.
+func Mul(a, b *Matrix) (*Matrix, error) {
+	if a.Cols != b.Rows {
+		return nil, fmt.Errorf("%w: cannot multiply %dx%d and %dx%d matrices", ErrDimension, a.Rows, a.Cols, b.Rows, b.Cols)
+	}
+	res := Zeros(a.Rows, b.Cols)
+	for i := 0; i < a.Rows; i++ {
+		for k := 0; k < a.Cols; k++ {
+			v := a.At(i, k)
+			if v == 0 {
+				continue
+			}
+			for j := 0; j < b.Cols; j++ {
+				res.Data[i*res.Cols+j] += v * b.At(k, j)
+			}
+		}
+	}
+	return res, nil
+}
+
+// Transpose returns transposed matrix.
+func Transpose(m *Matrix) *Matrix {
+	res := Zeros(m.Cols, m.Rows)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			res.Set(j, i, m.At(i, j))
+		}
+	}
+	return res
+}
+
+// lu is LU decomposition with partial pivoting, L and U share one matrix.
+type lu struct {
+	m    *Matrix
+	perm []int
+	sign float64
+}
+
+// decompose factorizes square matrix m. Pivot smaller than tolerance
+// relative to the largest value of m is treated as zero.
+func decompose(m *Matrix) (*lu, bool) {
+	n := m.Rows
+	a := m.clone()
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	maxAbs := 0.0
+	for _, v := range a.Data {
+		maxAbs = math.Max(maxAbs, math.Abs(v))
+	}
+	tol := maxAbs * float64(n) * 1e-14
+
+	sign := 1.0
+	singular := false
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a.At(i, k)) > math.Abs(a.At(p, k)) {
+				p = i
+			}
+		}
+		if math.Abs(a.At(p, k)) <= tol {
+			singular = true
+			continue
+		}
+		if p != k {
+			a.swapRows(p, k)
+			perm[p], perm[k] = perm[k], perm[p]
+			sign = -sign
+		}
+		for i := k + 1; i < n; i++ {
+			f := a.At(i, k) / a.At(k, k)
+			a.Set(i, k, f)
+			for j := k + 1; j < n; j++ {
+				a.Data[i*n+j] -= f * a.At(k, j)
+			}
+		}
+	}
+	return &lu{m: a, perm: perm, sign: sign}, !singular
+}
+
+// solve solves system for right hand side b using decomposition.
+func (d *lu) solve(b []float64) []float64 {
+	n := d.m.Rows
+	x := make([]float64, n)
+	for i := 0; i < n; i++ {
+		x[i] = b[d.perm[i]]
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= d.m.At(i, j) * x[j]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= d.m.At(i, j) * x[j]
+		}
+		x[i] /= d.m.At(i, i)
+	}
+	return x
+}
+
+func requireSquare(m *Matrix) error {
+	if m.Rows != m.Cols {
+		return fmt.Errorf("%w: %dx%d matrix is not square", ErrDimension, m.Rows, m.Cols)
+	}
+	return nil
+}
+
+// Det returns determinant of square matrix m.
+func Det(m *Matrix) (float64, error) {
+	if err := requireSquare(m); err != nil {
+		return 0, err
+	}
+	d, ok := decompose(m)
+	if !ok {
+		return 0, nil
+	}
+	det := d.sign
+	for i := 0; i < m.Rows; i++ {
+		det *= d.m.At(i, i)
+	}
+	return det, nil
+}
+
+// Inverse returns inverse of square matrix m.
+func Inverse(m *Matrix) (*Matrix, error) {
+	if err := requireSquare(m); err != nil {
+		return nil, err
+	}
+	d, ok := decompose(m)
+	if !ok {
+		return nil, ErrSingular
+	}
+
+	n := m.Rows
+	res := Zeros(n, n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		for i := range e {
+			e[i] = 0
+		}
+		e[j] = 1
+		col := d.solve(e)
+		for i := 0; i < n; i++ {
+			res.Set(i, j, col[i])
+		}
+	}
+	return res, nil
+}
+
+// Solve returns x such that a * x = b for square matrix a.
+func Solve(a *Matrix, b []float64) ([]float64, error) {
+	if err := requireSquare(a); err != nil {
+		return nil, err
+	}
+	if len(b) != a.Rows {
+		return nil, fmt.Errorf("%w: %dx%d matrix and vector of length %d", ErrDimension, a.Rows, a.Cols, len(b))
+	}
+	d, ok := decompose(a)
+	if !ok {
+		return nil, ErrSingular
+	}
+	return d.solve(b), nil
+}
@@ -0,0 +1,115 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func mustNew(t *testing.T, rows, cols int, data ...float64) *Matrix {
+	t.Helper()
+	m, err := New(rows, cols, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(2, 2, []float64{1, 2, 3}); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+	if _, err := New(0, 2, nil); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestDot(t *testing.T) {
+	v, err := Dot([]float64{1, 2, 3}, []float64{4, 5, 6})
+	if err != nil || v != 32 {
+		t.Errorf("got %v, %v, want 32", v, err)
+	}
+	if _, err := Dot([]float64{1}, []float64{1, 2}); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestMulTranspose(t *testing.T) {
+	a := mustNew(t, 2, 3, 1, 2, 3, 4, 5, 6)
+	b := Transpose(a)
+	if b.Rows != 3 || b.Cols != 2 || !equal(b.Data, []float64{1, 4, 2, 5, 3, 6}) {
+		t.Fatalf("unexpected transpose %+v", b)
+	}
+	c, err := Mul(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Rows != 2 || c.Cols != 2 || !equal(c.Data, []float64{14, 32, 32, 77}) {
+		t.Errorf("unexpected product %+v", c)
+	}
+	if _, err := Mul(a, a); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestDet(t *testing.T) {
+	tests := []struct {
+		m    *Matrix
+		want float64
+	}{
+		{mustNew(t, 1, 1, 5), 5},
+		{mustNew(t, 2, 2, 1, 2, 3, 4), -2},
+		{mustNew(t, 3, 3, 0, 1, 2, 1, 0, 3, 4, -3, 8), -2},
+		{mustNew(t, 2, 2, 1, 2, 2, 4), 0},
+	}
+	for _, tt := range tests {
+		got, err := Det(tt.m)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Det(%v) = %v, %v, want %v", tt.m.Data, got, err, tt.want)
+		}
+	}
+	if _, err := Det(mustNew(t, 1, 2, 1, 2)); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	m := mustNew(t, 3, 3, 0, 1, 2, 1, 0, 3, 4, -3, 8)
+	inv, err := Inverse(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := Mul(m, inv)
+	if !equal(p.Data, Identity(3).Data) {
+		t.Errorf("m * inverse(m) = %v", p.Data)
+	}
+	if _, err := Inverse(mustNew(t, 2, 2, 1, 2, 2, 4)); err != ErrSingular {
+		t.Errorf("got %v, want %v", err, ErrSingular)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	a := mustNew(t, 3, 3, 2, 1, -1, -3, -1, 2, -2, 1, 2)
+	x, err := Solve(a, []float64{8, -11, -3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(x, []float64{2, 3, -1}) {
+		t.Errorf("got %v, want [2 3 -1]", x)
+	}
+	if _, err := Solve(a, []float64{1}); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
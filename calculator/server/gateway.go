@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"grpc-udemy/calculator/calculatorpb"
+	"log"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newGateway creates HTTP/JSON gateway which proxies REST calls to gRPC server
+// listening on grpcAddr. Streaming RPCs are served as newline-delimited JSON.
+// Server metrics are exposed on /debug/vars.
+func newGateway(ctx context.Context, grpcAddr, httpAddr string) (*http.Server, error) {
+	mux := runtime.NewServeMux()
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+
+	if err := calculatorpb.RegisterCalculatorHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
+		log.Printf("failed to register calculator gateway: %v", err)
+		return nil, err
+	}
+
+	root := http.NewServeMux()
+	root.Handle("/debug/vars", expvar.Handler())
+	root.Handle("/", mux)
+
+	return &http.Server{Addr: httpAddr, Handler: root}, nil
+}
@@ -2,116 +2,83 @@ package main
 
 import (
 	"context"
+	"expvar"
+	"flag"
+	"grpc-udemy/calculator/cache"
 	"grpc-udemy/calculator/calculatorpb"
-	"io"
+	"grpc-udemy/calculator/calculatorsvc"
+	"grpc-udemy/deadline"
+	"grpc-udemy/web"
 	"log"
-	"math"
 	"net"
+	"net/http"
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
 )
 
-type server struct {
-	calculatorpb.UnimplementedCalculatorServer
-}
-
-func (s *server) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
-	res := &calculatorpb.SumResponse{
-		Sum: req.X + req.Y,
-	}
-	return res, nil
-}
+var (
+	addr        = flag.String("addr", ":50051", "gRPC listen address")
+	gatewayAddr = flag.String("gateway-addr", ":8080", "HTTP/JSON gateway listen address")
+	webAddr     = flag.String("web-addr", ":8081", "gRPC-Web listen address")
+	corsOrigins = flag.String("cors-origins", "*", "comma separated list of origins allowed to use gRPC-Web")
 
-// PrimeNumberDecomposition algorithm pseudocode:
-// k = 2
-// N = 210
-// while N > 1:
-//     if N % k == 0:   // if k evenly divides into N
-//         print k      // this is a factor
-//         N = N / k    // divide N by k so that we have the rest of the number left.
-//     else:
-//         k = k + 1
-func (s *server) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.Calculator_PrimeNumberDecompositionServer) error {
-	n := req.Number
-	k := int32(2)
+	cacheEntries = flag.Int("cache-entries", 10000, "maximum number of cached responses, 0 disables the cache")
+	cacheBytes   = flag.Int64("cache-bytes", 64<<20, "maximum total size of cached responses in bytes")
+	cacheTTL     = flag.Duration("cache-ttl", 0, "lifetime of cached responses, 0 means no expiration")
 
-	for n > 1 {
-		if n%k == 0 {
-			stream.Send(&calculatorpb.PrimeNumberDecompositionResponse{
-				Number: k,
-			})
-			n = n / k
-		} else {
-			k = k + 1
-		}
-	}
-	return nil
-}
-
-func (s *server) ComputeAverage(stream calculatorpb.Calculator_ComputeAverageServer) error {
-	sum := 0.0
-	count := 0
+	sessionTTL      = flag.Duration("session-ttl", 10*time.Minute, "how long calculator sessions are kept after their last stream ends")
+	defaultDeadline = flag.Duration("default-deadline", 10*time.Second, "deadline of unary calls which come without one, 0 means none")
+	maxDeadline     = flag.Duration("max-deadline", time.Minute, "longest deadline allowed for unary calls, 0 means no limit")
+)
 
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return stream.SendAndClose(&calculatorpb.AverageResponse{
-				Number: sum / float64(count),
-			})
-		}
-		sum += req.Number
-		count++
+// newCache returns response cache configured by flags, or nil if caching is
+// disabled. Cache statistics are published as expvar "calculator_cache".
+func newCache() *cache.Cache {
+	if *cacheEntries <= 0 {
+		return nil
 	}
+	c := cache.New(cache.Config{MaxEntries: *cacheEntries, MaxBytes: *cacheBytes, TTL: *cacheTTL})
+	expvar.Publish("calculator_cache", expvar.Func(func() interface{} { return c.Stats() }))
+	return c
 }
 
-func (s *server) FindMaximum(stream calculatorpb.Calculator_FindMaximumServer) error {
-	max := int32(math.MinInt32)
-
-	for {
-		req, err := stream.Recv()
-		if err != nil {
-			if err == io.EOF {
-				return nil
-			}
-			log.Fatalf("failed to recv: %v", err)
-			return err
-		}
-		if req.Number > max {
-			max = req.Number
-			time.Sleep(1000 * time.Millisecond)
+func main() {
+	flag.Parse()
 
-			stream.Send(&calculatorpb.MaximumResponse{
-				Number: max,
-			})
-		}
-	}
-}
+	lis, err := net.Listen("tcp", *addr)
 
-func (s *server) SquareRoot(ctx context.Context, req *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
-	if req.Number < 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "number %v is negative", req.Number)
+	if err != nil {
+		log.Fatalf("failed to listen %v", err)
 	}
 
-	return &calculatorpb.SquareRootResponse{
-		NumberRoot: math.Sqrt(float64(req.Number)),
-	}, nil
-}
+	svc := calculatorsvc.New(calculatorsvc.Config{
+		Cache:      newCache(),
+		SessionTTL: *sessionTTL,
+		Deadline:   deadline.Limit{Default: *defaultDeadline, Max: *maxDeadline},
+	})
+	s := grpc.NewServer(svc.ServerOptions()...)
+	calculatorpb.RegisterCalculatorServer(s, svc)
 
-func main() {
-	lis, err := net.Listen("tcp", ":50051")
+	reflection.Register(s)
 
+	gw, err := newGateway(context.Background(), lis.Addr().String(), *gatewayAddr)
 	if err != nil {
-		log.Fatalf("failed to listen %v", err)
+		log.Fatalf("failed to create gateway: %v", err)
 	}
+	go func() {
+		if err := gw.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve gateway: %v", err)
+		}
+	}()
 
-	s := grpc.NewServer()
-	calculatorpb.RegisterCalculatorServer(s, &server{})
-
-	reflection.Register(s)
+	ws := web.NewServer(*webAddr, s, web.CorsConfig{AllowedOrigins: web.ParseOrigins(*corsOrigins)})
+	go func() {
+		if err := ws.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve gRPC-Web: %v", err)
+		}
+	}()
 
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("Failed to server: %v", err)
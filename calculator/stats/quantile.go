@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Quantile estimates p-quantile of a stream with P² algorithm
+// (Jain and Chlamtac, 1985) using five markers, so memory does not grow
+// with number of observations. First five observations give exact result.
+type Quantile struct {
+	p       float64
+	n       int
+	heights [5]float64 // marker heights
+	pos     [5]float64 // actual marker positions
+	desired [5]float64 // desired marker positions
+	inc     [5]float64 // increments of desired positions
+}
+
+// NewQuantile creates estimator of p-quantile, p is in range [0, 1].
+func NewQuantile(p float64) *Quantile {
+	return &Quantile{
+		p:       p,
+		desired: [5]float64{0, 2 * p, 4 * p, 2 + 2*p, 4},
+		inc:     [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// Add adds observation x.
+func (q *Quantile) Add(x float64) {
+	if q.n < 5 {
+		q.heights[q.n] = x
+		q.n++
+		if q.n == 5 {
+			sort.Float64s(q.heights[:])
+			for i := range q.pos {
+				q.pos[i] = float64(i)
+			}
+		}
+		return
+	}
+	q.n++
+
+	// find cell k such that heights[k] <= x < heights[k+1], extending extremes
+	var k int
+	switch {
+	case x < q.heights[0]:
+		q.heights[0] = x
+		k = 0
+	case x >= q.heights[4]:
+		q.heights[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3; k++ {
+			if x < q.heights[k+1] {
+				break
+			}
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		q.pos[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.inc[i]
+	}
+
+	// adjust heights of middle markers
+	for i := 1; i <= 3; i++ {
+		d := q.desired[i] - q.pos[i]
+		if (d >= 1 && q.pos[i+1]-q.pos[i] > 1) || (d <= -1 && q.pos[i-1]-q.pos[i] < -1) {
+			sign := math.Copysign(1, d)
+			h := q.parabolic(i, sign)
+			if q.heights[i-1] < h && h < q.heights[i+1] {
+				q.heights[i] = h
+			} else {
+				q.heights[i] = q.linear(i, sign)
+			}
+			q.pos[i] += sign
+		}
+	}
+}
+
+func (q *Quantile) parabolic(i int, d float64) float64 {
+	return q.heights[i] + d/(q.pos[i+1]-q.pos[i-1])*
+		((q.pos[i]-q.pos[i-1]+d)*(q.heights[i+1]-q.heights[i])/(q.pos[i+1]-q.pos[i])+
+			(q.pos[i+1]-q.pos[i]-d)*(q.heights[i]-q.heights[i-1])/(q.pos[i]-q.pos[i-1]))
+}
+
+func (q *Quantile) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return q.heights[i] + d*(q.heights[j]-q.heights[i])/(q.pos[j]-q.pos[i])
+}
+
+// Value returns current estimate, zero when there are no observations.
+func (q *Quantile) Value() float64 {
+	if q.n == 0 {
+		return 0
+	}
+	if q.n <= 5 {
+		// exact quantile with linear interpolation between closest ranks
+		v := make([]float64, q.n)
+		copy(v, q.heights[:q.n])
+		sort.Float64s(v)
+		r := q.p * float64(q.n-1)
+		lo := int(math.Floor(r))
+		hi := int(math.Ceil(r))
+		return v[lo] + (r-float64(lo))*(v[hi]-v[lo])
+	}
+	return q.heights[2]
+}
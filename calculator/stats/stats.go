@@ -0,0 +1,118 @@
+// Package stats computes descriptive statistics over streams of numbers in
+// constant memory.
+package stats
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrEmpty is returned when statistics are requested before any number was added.
+var ErrEmpty = errors.New("no numbers")
+
+// ErrNotFinite is returned when NaN or infinite number is added.
+var ErrNotFinite = errors.New("number is not finite")
+
+// ErrOverflow is returned when number is too large in magnitude compared to
+// numbers added before, so sum, range or variance would overflow.
+var ErrOverflow = errors.New("statistics overflow with this number")
+
+// Summary holds statistics of numbers added so far.
+type Summary struct {
+	Count    int64
+	Sum      float64
+	Min      float64
+	Max      float64
+	Mean     float64
+	Variance float64 // sample variance, zero for single number
+	Stddev   float64
+	Median   float64
+	P90      float64
+	P99      float64
+}
+
+// Stream accumulates numbers. Mean and variance are computed with Welford's
+// algorithm, sum with Neumaier compensated summation and percentiles are
+// approximated with P² quantile estimators. Zero value is not usable, use New.
+type Stream struct {
+	count     int64
+	sum, comp float64
+	min, max  float64
+	mean, m2  float64
+
+	median, p90, p99 *Quantile
+}
+
+// New creates empty stream.
+func New() *Stream {
+	return &Stream{
+		median: NewQuantile(0.5),
+		p90:    NewQuantile(0.9),
+		p99:    NewQuantile(0.99),
+	}
+}
+
+// Add adds number x to the stream.
+func (s *Stream) Add(x float64) error {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return ErrNotFinite
+	}
+
+	// the update is computed before the stream is changed, so numbers which
+	// would overflow are rejected and the stream stays usable
+	count := s.count + 1
+	min, max := s.min, s.max
+	if count == 1 || x < min {
+		min = x
+	}
+	if count == 1 || x > max {
+		max = x
+	}
+	t := s.sum + x
+	delta := x - s.mean
+	mean := s.mean + delta/float64(count)
+	m2 := s.m2 + delta*(x-mean)
+	// finite range also keeps quantile marker heights finite
+	if math.IsInf(t, 0) || math.IsInf(max-min, 0) || math.IsInf(m2, 0) {
+		return ErrOverflow
+	}
+
+	s.count = count
+	s.min, s.max = min, max
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.comp += (s.sum - t) + x
+	} else {
+		s.comp += (x - t) + s.sum
+	}
+	s.sum = t
+	s.mean, s.m2 = mean, m2
+
+	s.median.Add(x)
+	s.p90.Add(x)
+	s.p99.Add(x)
+	return nil
+}
+
+// Summary returns statistics of numbers added so far.
+func (s *Stream) Summary() (Summary, error) {
+	if s.count == 0 {
+		return Summary{}, ErrEmpty
+	}
+
+	variance := 0.0
+	if s.count > 1 {
+		variance = s.m2 / float64(s.count-1)
+	}
+	return Summary{
+		Count:    s.count,
+		Sum:      s.sum + s.comp,
+		Min:      s.min,
+		Max:      s.max,
+		Mean:     s.mean,
+		Variance: variance,
+		Stddev:   math.Sqrt(variance),
+		Median:   s.median.Value(),
+		P90:      s.p90.Value(),
+		P99:      s.p99.Value(),
+	}, nil
+}
@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestEmpty(t *testing.T) {
+	if _, err := New().Summary(); err != ErrEmpty {
+		t.Fatalf("got %v, want %v", err, ErrEmpty)
+	}
+}
+
+func TestNotFinite(t *testing.T) {
+	s := New()
+	for _, x := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if err := s.Add(x); err != ErrNotFinite {
+			t.Errorf("Add(%v) = %v, want %v", x, err, ErrNotFinite)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		numbers []float64
+		last    float64 // rejected
+	}{
+		{[]float64{1e308}, 1e308},        // sum
+		{[]float64{-1e308}, -1e308},      // sum
+		{[]float64{1e308}, -1e308},       // range
+		{[]float64{math.MaxFloat64}, -1}, // range
+		{[]float64{1e154}, -1e155},       // variance
+	}
+	for _, tt := range tests {
+		s := New()
+		for _, x := range tt.numbers {
+			if err := s.Add(x); err != nil {
+				t.Fatalf("Add(%v) = %v", x, err)
+			}
+		}
+		before, _ := s.Summary()
+		if err := s.Add(tt.last); err != ErrOverflow {
+			t.Errorf("Add(%v) after %v = %v, want %v", tt.last, tt.numbers, err, ErrOverflow)
+		}
+		after, _ := s.Summary()
+		if after != before {
+			t.Errorf("rejected Add(%v) changed summary from %+v to %+v", tt.last, before, after)
+		}
+	}
+
+	s := New()
+	for _, x := range []float64{1e150, 2e150, 3e150} {
+		if err := s.Add(x); err != nil {
+			t.Fatalf("Add(%v) = %v", x, err)
+		}
+	}
+	sum, _ := s.Summary()
+	if math.IsInf(sum.Variance, 0) || math.IsNaN(sum.Variance) || math.Abs(sum.Variance-1e300) > 1e286 {
+		t.Errorf("summary of large numbers = %+v", sum)
+	}
+}
+
+func TestSmall(t *testing.T) {
+	s := New()
+	for _, x := range []float64{1, 2, 3, 4} {
+		s.Add(x)
+	}
+	sum, err := s.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Summary{Count: 4, Sum: 10, Min: 1, Max: 4, Mean: 2.5, Variance: 5.0 / 3, Stddev: math.Sqrt(5.0 / 3), Median: 2.5, P90: 3.7, P99: 3.97}
+	if !approxEqual(sum, want, 1e-12) {
+		t.Errorf("got %+v, want %+v", sum, want)
+	}
+}
+
+func TestSingle(t *testing.T) {
+	s := New()
+	s.Add(42)
+	sum, _ := s.Summary()
+	want := Summary{Count: 1, Sum: 42, Min: 42, Max: 42, Mean: 42, Median: 42, P90: 42, P99: 42}
+	if !approxEqual(sum, want, 0) {
+		t.Errorf("got %+v, want %+v", sum, want)
+	}
+}
+
+// Naive sum of squares loses all precision for large offset, Welford does not.
+func TestNumericallyStable(t *testing.T) {
+	s := New()
+	for _, x := range []float64{4, 7, 13, 16} {
+		s.Add(1e9 + x)
+	}
+	sum, _ := s.Summary()
+	if math.Abs(sum.Variance-30) > 1e-6 {
+		t.Errorf("got variance %v, want 30", sum.Variance)
+	}
+}
+
+func TestQuantilesApproximate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := New()
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = r.NormFloat64()*10 + 100
+		s.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	sum, _ := s.Summary()
+	for _, q := range []struct {
+		name string
+		got  float64
+		p    float64
+	}{{"median", sum.Median, 0.5}, {"p90", sum.P90, 0.9}, {"p99", sum.P99, 0.99}} {
+		want := values[int(q.p*float64(len(values)))]
+		if math.Abs(q.got-want) > 0.5 {
+			t.Errorf("%s = %v, want about %v", q.name, q.got, want)
+		}
+	}
+}
+
+func approxEqual(a, b Summary, eps float64) bool {
+	if a.Count != b.Count {
+		return false
+	}
+	x := []float64{a.Sum, a.Min, a.Max, a.Mean, a.Variance, a.Stddev, a.Median, a.P90, a.P99}
+	y := []float64{b.Sum, b.Min, b.Max, b.Mean, b.Variance, b.Stddev, b.Median, b.P90, b.P99}
+	for i := range x {
+		if math.Abs(x[i]-y[i]) > eps {
+			return false
+		}
+	}
+	return true
+}
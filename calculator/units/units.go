@@ -0,0 +1,261 @@
+// Package units implements physical quantities with dimensional analysis.
+//
+// Units are written as products and quotients of unit symbols with optional
+// integer exponents, e.g. "km/h", "kg*m/s^2" or "m^3". Operators are applied
+// left to right, so "m/s/s" equals "m/s^2". Symbols may take SI prefixes
+// (k, M, m, u, µ, n, ...). Temperatures are supported in kelvin only.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownUnit is returned for unit expressions which cannot be parsed.
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrIncompatible is returned when dimensions do not fit the operation.
+	ErrIncompatible = errors.New("incompatible units")
+)
+
+// Dimension holds exponents of SI base units in order m, kg, s, A, K, mol, cd.
+type Dimension [7]int
+
+var baseSymbols = [7]string{"m", "kg", "s", "A", "K", "mol", "cd"}
+
+func (d Dimension) mul(o Dimension, sign int) Dimension {
+	for i := range d {
+		d[i] += sign * o[i]
+	}
+	return d
+}
+
+// String formats d using SI base units, e.g. "kg*m/s^2". Dimensionless is "".
+func (d Dimension) String() string {
+	var num, den []string
+	for i, e := range d {
+		switch {
+		case e > 0:
+			num = append(num, power(baseSymbols[i], e))
+		case e < 0:
+			den = append(den, power(baseSymbols[i], -e))
+		}
+	}
+	if len(num) == 0 && len(den) == 0 {
+		return ""
+	}
+	s := strings.Join(num, "*")
+	if s == "" {
+		s = "1"
+	}
+	for _, u := range den {
+		s += "/" + u
+	}
+	return s
+}
+
+func power(symbol string, e int) string {
+	if e == 1 {
+		return symbol
+	}
+	return symbol + "^" + strconv.Itoa(e)
+}
+
+// Unit is scale of the unit relative to SI base units and its dimension.
+type Unit struct {
+	Scale float64
+	Dim   Dimension
+}
+
+type unitDef struct {
+	Unit
+	prefixable bool
+}
+
+func def(scale float64, prefixable bool, dim ...int) unitDef {
+	var d Dimension
+	copy(d[:], dim)
+	return unitDef{Unit{scale, d}, prefixable}
+}
+
+var units = map[string]unitDef{
+	// SI base units, kilogram is prefixed through gram.
+	"m":   def(1, true, 1),
+	"g":   def(1e-3, true, 0, 1),
+	"s":   def(1, true, 0, 0, 1),
+	"A":   def(1, true, 0, 0, 0, 1),
+	"K":   def(1, true, 0, 0, 0, 0, 1),
+	"mol": def(1, true, 0, 0, 0, 0, 0, 1),
+	"cd":  def(1, true, 0, 0, 0, 0, 0, 0, 1),
+	// derived SI units
+	"Hz":  def(1, true, 0, 0, -1),
+	"N":   def(1, true, 1, 1, -2),
+	"Pa":  def(1, true, -1, 1, -2),
+	"J":   def(1, true, 2, 1, -2),
+	"W":   def(1, true, 2, 1, -3),
+	"C":   def(1, true, 0, 0, 1, 1),
+	"V":   def(1, true, 2, 1, -3, -1),
+	"ohm": def(1, true, 2, 1, -3, -2),
+	"Ω":   def(1, true, 2, 1, -3, -2),
+	"L":   def(1e-3, true, 3),
+	"l":   def(1e-3, true, 3),
+	// other units
+	"min": def(60, false, 0, 0, 1),
+	"h":   def(3600, false, 0, 0, 1),
+	"d":   def(86400, false, 0, 0, 1),
+	"in":  def(0.0254, false, 1),
+	"ft":  def(0.3048, false, 1),
+	"yd":  def(0.9144, false, 1),
+	"mi":  def(1609.344, false, 1),
+	"lb":  def(0.45359237, false, 0, 1),
+	"oz":  def(0.028349523125, false, 0, 1),
+	"t":   def(1000, false, 0, 1),
+	"bar": def(1e5, false, -1, 1, -2),
+	"atm": def(101325, false, -1, 1, -2),
+	"cal": def(4.184, true, 2, 1, -2),
+	"eV":  def(1.602176634e-19, true, 2, 1, -2),
+	"Wh":  def(3600, true, 2, 1, -2),
+}
+
+var prefixes = map[string]float64{
+	"Y": 1e24, "Z": 1e21, "E": 1e18, "P": 1e15, "T": 1e12, "G": 1e9, "M": 1e6,
+	"k": 1e3, "h": 1e2, "da": 1e1, "d": 1e-1, "c": 1e-2, "m": 1e-3,
+	"u": 1e-6, "µ": 1e-6, "μ": 1e-6, "n": 1e-9, "p": 1e-12, "f": 1e-15, "a": 1e-18,
+}
+
+func lookup(symbol string) (Unit, bool) {
+	if u, ok := units[symbol]; ok {
+		return u.Unit, true
+	}
+	for p, scale := range prefixes {
+		if !strings.HasPrefix(symbol, p) {
+			continue
+		}
+		if u, ok := units[symbol[len(p):]]; ok && u.prefixable {
+			return Unit{u.Scale * scale, u.Dim}, true
+		}
+	}
+	return Unit{}, false
+}
+
+// Parse parses unit expression. Empty string and "1" are dimensionless.
+func Parse(s string) (Unit, error) {
+	u := Unit{Scale: 1}
+	sign := 1
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		i := strings.IndexAny(rest, "*/")
+		term := rest
+		if i >= 0 {
+			term = rest[:i]
+		}
+		t, err := parseTerm(strings.TrimSpace(term))
+		if err != nil {
+			return Unit{}, fmt.Errorf("%w %q in %q", ErrUnknownUnit, term, s)
+		}
+		if sign > 0 {
+			u.Scale *= t.Scale
+		} else {
+			u.Scale /= t.Scale
+		}
+		u.Dim = u.Dim.mul(t.Dim, sign)
+		if i < 0 {
+			break
+		}
+		sign = 1
+		if rest[i] == '/' {
+			sign = -1
+		}
+		rest = rest[i+1:]
+		if strings.TrimSpace(rest) == "" {
+			return Unit{}, fmt.Errorf("%w: %q ends with operator", ErrUnknownUnit, s)
+		}
+	}
+	return u, nil
+}
+
+func parseTerm(term string) (Unit, error) {
+	if term == "1" {
+		return Unit{Scale: 1}, nil
+	}
+	exp := 1
+	if i := strings.IndexByte(term, '^'); i >= 0 {
+		e, err := strconv.Atoi(term[i+1:])
+		if err != nil {
+			return Unit{}, err
+		}
+		exp, term = e, term[:i]
+	}
+	u, ok := lookup(term)
+	if !ok {
+		return Unit{}, ErrUnknownUnit
+	}
+	var d Dimension
+	for i := range d {
+		d[i] = u.Dim[i] * exp
+	}
+	return Unit{math.Pow(u.Scale, float64(exp)), d}, nil
+}
+
+// Quantity is value in SI base units with its dimension.
+type Quantity struct {
+	Value float64
+	Dim   Dimension
+}
+
+// New creates quantity from value expressed in unit.
+func New(value float64, unit string) (Quantity, error) {
+	u, err := Parse(unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{value * u.Scale, u.Dim}, nil
+}
+
+// In returns value of q expressed in unit.
+func (q Quantity) In(unit string) (float64, error) {
+	u, err := Parse(unit)
+	if err != nil {
+		return 0, err
+	}
+	if u.Dim != q.Dim {
+		return 0, fmt.Errorf("%w: cannot convert %s to %s", ErrIncompatible, dimName(q.Dim), unit)
+	}
+	return q.Value / u.Scale, nil
+}
+
+func dimName(d Dimension) string {
+	if s := d.String(); s != "" {
+		return s
+	}
+	return "dimensionless"
+}
+
+// Add returns a + b, which must have the same dimension.
+func Add(a, b Quantity) (Quantity, error) {
+	if a.Dim != b.Dim {
+		return Quantity{}, fmt.Errorf("%w: cannot add %s and %s", ErrIncompatible, dimName(a.Dim), dimName(b.Dim))
+	}
+	return Quantity{a.Value + b.Value, a.Dim}, nil
+}
+
+// Sub returns a - b, which must have the same dimension.
+func Sub(a, b Quantity) (Quantity, error) {
+	if a.Dim != b.Dim {
+		return Quantity{}, fmt.Errorf("%w: cannot subtract %s from %s", ErrIncompatible, dimName(b.Dim), dimName(a.Dim))
+	}
+	return Quantity{a.Value - b.Value, a.Dim}, nil
+}
+
+// Mul returns a * b.
+func Mul(a, b Quantity) Quantity {
+	return Quantity{a.Value * b.Value, a.Dim.mul(b.Dim, 1)}
+}
+
+// Div returns a / b.
+func Div(a, b Quantity) Quantity {
+	return Quantity{a.Value / b.Value, a.Dim.mul(b.Dim, -1)}
+}
@@ -0,0 +1,93 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{1, "km", "m", 1000},
+		{36, "km/h", "m/s", 10},
+		{1, "mi", "ft", 5280},
+		{1, "N", "kg*m/s^2", 1},
+		{1, "kWh", "J", 3.6e6},
+		{1, "J", "eV", 1 / 1.602176634e-19},
+		{2, "L", "cm^3", 2000},
+		{1, "m/s/s", "m/s^2", 1},
+		{5, "1/s", "Hz", 5},
+		{3, "", "1", 3},
+		{1, "µs", "ns", 1000},
+		{1, "min", "s", 60},
+		{1, "mm", "m", 1e-3},
+		{1, "mol", "mmol", 1000},
+		{1, "cd", "cd", 1},
+		{1, "atm", "kPa", 101.325},
+	}
+	for _, tt := range tests {
+		q, err := New(tt.value, tt.from)
+		if err != nil {
+			t.Errorf("New(%v, %q) failed: %v", tt.value, tt.from, err)
+			continue
+		}
+		got, err := q.In(tt.to)
+		if err != nil {
+			t.Errorf("%v %s in %s failed: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9*math.Abs(tt.want) {
+			t.Errorf("%v %s = %v %s, want %v", tt.value, tt.from, got, tt.to, tt.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, u := range []string{"foo", "m^x", "m/", "kkm", "ha"} {
+		if _, err := Parse(u); !errors.Is(err, ErrUnknownUnit) {
+			t.Errorf("Parse(%q) = %v, want ErrUnknownUnit", u, err)
+		}
+	}
+	q, _ := New(1, "m")
+	if _, err := q.In("s"); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("converting m to s returned %v", err)
+	}
+	s, _ := New(1, "s")
+	if _, err := Add(q, s); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("adding m and s returned %v", err)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d, _ := New(100, "m")
+	tm, _ := New(10, "s")
+	v := Div(d, tm)
+	if got := v.Dim.String(); got != "m/s" {
+		t.Errorf("dimension of m/s is %q", got)
+	}
+	if got, _ := v.In("km/h"); math.Abs(got-36) > 1e-9 {
+		t.Errorf("100 m / 10 s = %v km/h, want 36", got)
+	}
+	m, _ := New(2, "kg")
+	e := Mul(m, Mul(v, v))
+	if got := e.Dim.String(); got != "m^2*kg/s^2" {
+		t.Errorf("dimension of energy is %q", got)
+	}
+	if got, _ := e.In("J"); got != 200 {
+		t.Errorf("2 kg * (10 m/s)^2 = %v J, want 200", got)
+	}
+	sum, err := Add(d, d)
+	if err != nil || sum.Value != 200 {
+		t.Errorf("100 m + 100 m = %v, %v", sum, err)
+	}
+	if got := Div(d, d).Dim.String(); got != "" {
+		t.Errorf("m/m is %q, want dimensionless", got)
+	}
+	if got := Div(Quantity{Value: 1}, tm).Dim.String(); got != "1/s" {
+		t.Errorf("1/s formatted as %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/streaming"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var blogCommands = map[string]command{
+	"create": doCreateBlog,
+	"read":   doReadBlog,
+	"update": doUpdateBlog,
+	"delete": doDeleteBlog,
+	"list":   doListBlog,
+}
+
+// blogFlags parses blog fields, set reports which of them were given.
+func blogFlags(name string, args []string) (blog *blogpb.Blog, set map[string]bool, rest []string, err error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	blog = &blogpb.Blog{}
+	fs.StringVar(&blog.AuthorId, "author", "", "author id")
+	fs.StringVar(&blog.Title, "title", "", "blog title")
+	fs.StringVar(&blog.Content, "content", "", "blog content")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, nil, usageErrorf("%v", err)
+	}
+	set = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return blog, set, fs.Args(), nil
+}
+
+func doCreateBlog(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	blog, _, _, err := blogFlags("create", args)
+	if err != nil {
+		return err
+	}
+	if blog.Title == "" {
+		return usageErrorf("-title is required")
+	}
+	res, err := blogpb.NewBlogServiceClient(conn).CreateBlog(ctx, &blogpb.CreateBlogRequest{Blog: blog})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Blog)
+}
+
+func doReadBlog(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("read requires blog id")
+	}
+	res, err := blogpb.NewBlogServiceClient(conn).ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Blog)
+}
+
+// doUpdateBlog updates only fields given as flags.
+func doUpdateBlog(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) < 1 {
+		return usageErrorf("update requires blog id")
+	}
+	blog, set, _, err := blogFlags("update", args[1:])
+	if err != nil {
+		return err
+	}
+	blog.Id = args[0]
+	mask := &fieldmaskpb.FieldMask{}
+	if set["author"] {
+		mask.Paths = append(mask.Paths, "author_id")
+	}
+	if set["title"] {
+		mask.Paths = append(mask.Paths, "title")
+	}
+	if set["content"] {
+		mask.Paths = append(mask.Paths, "content")
+	}
+
+	c := blogpb.NewBlogServiceClient(conn)
+	res, err := c.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: blog, UpdateMask: mask})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Blog)
+}
+
+func doDeleteBlog(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("delete requires blog id")
+	}
+	res, err := blogpb.NewBlogServiceClient(conn).DeleteBlog(ctx, &blogpb.DeleteBlogRequest{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Id)
+}
+
+// listResumeAttempts is how many times list resumes after UNAVAILABLE
+// errors without receiving any blog in between.
+const listResumeAttempts = 5
+
+// doListBlog lists blogs, resuming the listing when the stream breaks.
+func doListBlog(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	req := &blogpb.ListBlogRequest{}
+	batchSize := fs.Int("batch-size", 0, "blogs per message, 0 lets the server choose")
+	fs.BoolVar(&req.Adaptive, "adaptive", false, "let the server fill messages up to the size limit")
+	fs.StringVar(&req.ResumeToken, "resume", "", "resume token of interrupted listing")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+	req.BatchSize = int32(*batchSize)
+	client := blogpb.NewBlogServiceClient(conn)
+	for attempt := 1; ; attempt++ {
+		token := req.ResumeToken
+		err := listBlogs(ctx, client, req, out)
+		if status.Code(err) != codes.Unavailable {
+			return err
+		}
+		if req.ResumeToken != token {
+			attempt = 1
+		}
+		if attempt > listResumeAttempts {
+			return err
+		}
+		log.Printf("listing interrupted: %v, resuming", err)
+		if err := streaming.Sleep(ctx, time.Duration(attempt)*200*time.Millisecond); err != nil {
+			return err
+		}
+	}
+}
+
+// listBlogs prints blogs of single ListBlog stream and keeps resume token of
+// req pointing after the last printed one.
+func listBlogs(ctx context.Context, client blogpb.BlogServiceClient, req *blogpb.ListBlogRequest, out *printer) error {
+	stream, err := client.ListBlog(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, b := range res.Blog {
+			if err := out.print(b, b); err != nil {
+				return err
+			}
+		}
+		req.ResumeToken = res.ResumeToken
+	}
+}
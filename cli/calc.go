@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/streaming"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var calcCommands = map[string]command{
+	"sum":       doSum,
+	"primes":    doPrimeNumberDecomposition,
+	"average":   doComputeAverage,
+	"max":       doFindMaximum,
+	"sqrt":      doSquareRoot,
+	"eval":      doEvaluate,
+	"stats":     doComputeStatistics,
+	"running":   doRunningStatistics,
+	"batch":     doBatchCompute,
+	"dot":       doDotProduct,
+	"matmul":    doMatrixMultiply,
+	"transpose": doTranspose,
+	"det":       doDeterminant,
+	"inverse":   doInverse,
+	"solve":     doSolveLinearSystem,
+	"complex":   doComplexCompute,
+	"convert":   doConvertUnits,
+	"units":     doUnitCompute,
+	"session":   doSession,
+}
+
+// parseInteger parses s as int64, integers out of int64 range are returned
+// as decimal string to be sent in big integer fields.
+func parseInteger(s string) (int64, string, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, "", nil
+	}
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		return 0, n.String(), nil
+	}
+	return 0, "", usageErrorf("invalid number %q", s)
+}
+
+// integerValue returns bigValue when set, value otherwise.
+func integerValue(value int64, bigValue string) interface{} {
+	if bigValue != "" {
+		return bigValue
+	}
+	return value
+}
+
+// scanNumbers calls fn for every whitespace separated number read from r.
+func scanNumbers(r io.Reader, fn func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func doSum(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("sum requires exactly two numbers")
+	}
+	x, bigX, err := parseInteger(args[0])
+	if err != nil {
+		return err
+	}
+	y, bigY, err := parseInteger(args[1])
+	if err != nil {
+		return err
+	}
+	if bigX != "" || bigY != "" {
+		bigX, bigY = args[0], args[1]
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).Sum(ctx, &calculatorpb.SumRequest{X: x, Y: y, BigX: bigX, BigY: bigY})
+	if err != nil {
+		return err
+	}
+	return out.print(res, integerValue(res.Sum, res.BigSum))
+}
+
+func doSquareRoot(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	// negative numbers look like flags, so -complex is matched by hand
+	allowComplex := len(args) > 0 && args[0] == "-complex"
+	if allowComplex {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return usageErrorf("sqrt requires exactly one number")
+	}
+	n, bigN, err := parseInteger(args[0])
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).SquareRoot(ctx, &calculatorpb.SquareRootRequest{Number: n, BigNumber: bigN, AllowComplex: allowComplex})
+	if err != nil {
+		return err
+	}
+	root := fmt.Sprint(res.NumberRoot)
+	if res.BigNumberRoot != "" {
+		root = res.BigNumberRoot
+	}
+	if res.Imaginary {
+		root += "i"
+	}
+	return out.print(res, root)
+}
+
+func doPrimeNumberDecomposition(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("primes requires exactly one number")
+	}
+	n, bigN, err := parseInteger(args[0])
+	if err != nil {
+		return err
+	}
+	stream, err := calculatorpb.NewCalculatorClient(conn).PrimeNumberDecomposition(ctx, &calculatorpb.PrimeNumberDecompositionRequest{Number: n, BigNumber: bigN})
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := out.print(res, fmt.Sprintf("%v^%d", integerValue(res.Number, res.BigNumber), res.Multiplicity)); err != nil {
+			return err
+		}
+	}
+}
+
+// doComputeAverage reads numbers from stdin.
+func doComputeAverage(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	stream, err := calculatorpb.NewCalculatorClient(conn).ComputeAverage(ctx)
+	if err != nil {
+		return err
+	}
+	err = scanNumbers(os.Stdin, func(s string) error {
+		n, err := parseFloat(s)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&calculatorpb.AverageRequest{Number: n})
+	})
+	if err != nil && err != io.EOF {
+		return err
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Number)
+}
+
+// doFindMaximum reads numbers from stdin and prints every new maximum.
+func doFindMaximum(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("max", flag.ContinueOnError)
+	interval := fs.Duration("interval", -1, "delay before each emitted maximum, negative means server default")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+	var iv *durationpb.Duration
+	if *interval >= 0 {
+		iv = durationpb.New(*interval)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := calculatorpb.NewCalculatorClient(conn).FindMaximum(ctx)
+	if err != nil {
+		return err
+	}
+	return streaming.Duplex(stream, cancel, func(send func(interface{}) error) error {
+		return scanNumbers(os.Stdin, func(s string) error {
+			n, bigN, err := parseInteger(s)
+			if err != nil {
+				return err
+			}
+			req := &calculatorpb.MaximumRequest{Number: n, BigNumber: bigN, Interval: iv}
+			iv = nil
+			return send(req)
+		})
+	}, func() interface{} { return &calculatorpb.MaximumResponse{} }, func(m interface{}) error {
+		res := m.(*calculatorpb.MaximumResponse)
+		return out.print(res, integerValue(res.Number, res.BigNumber))
+	})
+}
+
+func formatStatistics(res *calculatorpb.StatisticsResponse) string {
+	return fmt.Sprintf("count=%d sum=%v min=%v max=%v mean=%v variance=%v stddev=%v median=%v p90=%v p99=%v",
+		res.Count, res.Sum, res.Min, res.Max, res.Mean, res.Variance, res.Stddev, res.Median, res.P90, res.P99)
+}
+
+func parseFloat(s string) (float64, error) {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, usageErrorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+// doComputeStatistics reads numbers from stdin.
+func doComputeStatistics(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	stream, err := calculatorpb.NewCalculatorClient(conn).ComputeStatistics(ctx)
+	if err != nil {
+		return err
+	}
+	err = scanNumbers(os.Stdin, func(s string) error {
+		n, err := parseFloat(s)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&calculatorpb.StatisticsRequest{Number: n})
+	})
+	if err != nil && err != io.EOF {
+		return err
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatStatistics(res))
+}
+
+// doRunningStatistics reads numbers from stdin and prints statistics after every number.
+func doRunningStatistics(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := calculatorpb.NewCalculatorClient(conn).RunningStatistics(ctx)
+	if err != nil {
+		return err
+	}
+	return streaming.Duplex(stream, cancel, func(send func(interface{}) error) error {
+		return scanNumbers(os.Stdin, func(s string) error {
+			n, err := parseFloat(s)
+			if err != nil {
+				return err
+			}
+			return send(&calculatorpb.StatisticsRequest{Number: n})
+		})
+	}, func() interface{} { return &calculatorpb.StatisticsResponse{} }, func(m interface{}) error {
+		res := m.(*calculatorpb.StatisticsResponse)
+		return out.print(res, formatStatistics(res))
+	})
+}
+
+// variables collects repeated -var name=value flags.
+type variables map[string]float64
+
+func (v variables) String() string {
+	return fmt.Sprint(map[string]float64(v))
+}
+
+func (v variables) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("variable %q is not in name=value form", s)
+	}
+	n, err := strconv.ParseFloat(kv[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid value of variable %q", kv[0])
+	}
+	v[kv[0]] = n
+	return nil
+}
+
+// doEvaluate evaluates expression, on error it points to the failing position.
+func doEvaluate(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	vars := variables{}
+	fs.Var(vars, "var", "variable binding name=value, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("eval requires exactly one expression")
+	}
+	expression := fs.Arg(0)
+
+	res, err := calculatorpb.NewCalculatorClient(conn).Evaluate(ctx, &calculatorpb.EvaluateRequest{
+		Expression: expression,
+		Variables:  vars,
+	})
+	if err != nil {
+		for _, d := range status.Convert(err).Details() {
+			if e, ok := d.(*calculatorpb.ExpressionError); ok {
+				fmt.Fprintf(os.Stderr, "%s\n%s^ %s\n", expression, strings.Repeat(" ", int(e.Position)), e.Message)
+			}
+		}
+		return err
+	}
+	return out.print(res, res.Result)
+}
+
+// doBatchCompute reads operations from stdin, one JSON encoded Operation per line,
+// e.g. {"id": "1", "sum": {"x": 3, "y": 5}}, and prints results as they arrive.
+func doBatchCompute(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := calculatorpb.NewCalculatorClient(conn).BatchComputeStream(ctx)
+	if err != nil {
+		return err
+	}
+	return streaming.Duplex(stream, cancel, func(send func(interface{}) error) error {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			op := &calculatorpb.Operation{}
+			if err := protojson.Unmarshal([]byte(line), op); err != nil {
+				return usageErrorf("invalid operation %q: %v", line, err)
+			}
+			if err := send(op); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}, func() interface{} { return &calculatorpb.OperationResult{} }, func(m interface{}) error {
+		res := m.(*calculatorpb.OperationResult)
+		return out.print(res, res)
+	})
+}
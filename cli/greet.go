@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"grpc-udemy/greet/greetpb"
+	"grpc-udemy/streaming"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var greetCommands = map[string]command{
+	"greet":     doGreet,
+	"many":      doGreetManyTimes,
+	"long":      doLongGreet,
+	"everyone":  doGreetEveryone,
+	"deadline":  doGreetWithDeadline,
+	"template":  doCreateTemplate,
+	"templates": doListTemplates,
+}
+
+func parseGreeting(name string, args []string) (*greetpb.Greeting, error) {
+	return parseGreetingFlags(flag.NewFlagSet(name, flag.ContinueOnError), args)
+}
+
+// parseGreetingFlags registers greeting flags in fs, which may already hold
+// method specific flags, and parses args.
+func parseGreetingFlags(fs *flag.FlagSet, args []string) (*greetpb.Greeting, error) {
+	first := fs.String("first", "", "first name")
+	last := fs.String("last", "", "last name")
+	locale := fs.String("locale", "", "locale of the greeting, e.g. de-CH")
+	title := fs.String("title", "", "title, e.g. Dr.")
+	preferred := fs.String("preferred", "", "preferred name")
+	tz := fs.String("tz", "", "IANA time zone of the person, e.g. Europe/Berlin")
+	occasion := fs.String("occasion", "", "occasion, e.g. birthday")
+	template := fs.String("template", "", "id of greeting template, see templates")
+	if err := fs.Parse(args); err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+	if *first == "" {
+		return nil, usageErrorf("-first is required")
+	}
+	return &greetpb.Greeting{
+		FirstName:     *first,
+		LastName:      *last,
+		Locale:        *locale,
+		Title:         *title,
+		PreferredName: *preferred,
+		TimeZone:      *tz,
+		Occasion:      *occasion,
+		TemplateId:    *template,
+	}, nil
+}
+
+func doGreet(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	greeting, err := parseGreeting("greet", args)
+	if err != nil {
+		return err
+	}
+	res, err := greetpb.NewGreetServiceClient(conn).Greet(ctx, &greetpb.GreetRequest{Greeting: greeting})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Result)
+}
+
+func doGreetWithDeadline(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	greeting, err := parseGreeting("deadline", args)
+	if err != nil {
+		return err
+	}
+	res, err := greetpb.NewGreetServiceClient(conn).GreetWithDeadline(ctx, &greetpb.GreetRequest{Greeting: greeting})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Result)
+}
+
+func doGreetManyTimes(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("many", flag.ContinueOnError)
+	count := fs.Int("count", 0, "number of greetings, 0 means server default")
+	interval := fs.Duration("interval", -1, "delay between greetings, negative means server default")
+	resume := fs.String("resume", "", "resume token of interrupted greeting")
+	greeting, err := parseGreetingFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	req := &greetpb.GreeetManyTimesRequest{Greeting: greeting, Count: int32(*count), ResumeToken: *resume}
+	if *interval >= 0 {
+		req.Interval = durationpb.New(*interval)
+	}
+	stream, err := greetpb.NewGreetServiceClient(conn).GreetManyTimes(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := out.print(res, res.Result); err != nil {
+			return err
+		}
+	}
+}
+
+// doLongGreet sends every argument as a first name.
+func doLongGreet(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("at least one name is required")
+	}
+	stream, err := greetpb.NewGreetServiceClient(conn).LongGreet(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := stream.Send(&greetpb.LongGreetRequest{Greeting: &greetpb.Greeting{FirstName: name}}); err != nil {
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Result)
+}
+
+// doGreetEveryone joins a room, sends names read from stdin line by line and
+// prints greetings and presence events of the room as they arrive.
+func doGreetEveryone(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("everyone", flag.ContinueOnError)
+	room := fs.String("room", "", "room to join, server default if empty")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := greetpb.NewGreetServiceClient(conn).GreetEveryone(ctx)
+	if err != nil {
+		return err
+	}
+	return streaming.Duplex(stream, cancel, func(send func(interface{}) error) error {
+		scanner := bufio.NewScanner(os.Stdin)
+		first := true
+		for scanner.Scan() {
+			name := strings.TrimSpace(scanner.Text())
+			if name == "" {
+				continue
+			}
+			req := &greetpb.GreetEveryoneRequest{Greeting: &greetpb.Greeting{FirstName: name}}
+			if first {
+				req.Room = *room
+				first = false
+			}
+			if err := send(req); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}, func() interface{} { return &greetpb.GreetEveryoneResponse{} }, func(m interface{}) error {
+		res := m.(*greetpb.GreetEveryoneResponse)
+		return out.print(res, formatRoomMessage(res))
+	})
+}
+
+func formatRoomMessage(res *greetpb.GreetEveryoneResponse) string {
+	var s string
+	switch res.Kind {
+	case greetpb.GreetEveryoneResponse_JOIN:
+		s = fmt.Sprintf("* %s joined %s", res.From, res.Room)
+	case greetpb.GreetEveryoneResponse_LEAVE:
+		s = fmt.Sprintf("* %s left %s", res.From, res.Room)
+	default:
+		s = fmt.Sprintf("<%s> %s", res.From, res.Result)
+	}
+	if res.Dropped > 0 {
+		s = fmt.Sprintf("(%d messages dropped) %s", res.Dropped, s)
+	}
+	return s
+}
+
+// doCreateTemplate creates template with text given as the only argument.
+func doCreateTemplate(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("template", flag.ContinueOnError)
+	name := fs.String("name", "", "template name")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("template requires text, e.g. \"{salutation}, {name}!\"")
+	}
+	res, err := greetpb.NewGreetServiceClient(conn).CreateTemplate(ctx, &greetpb.CreateTemplateRequest{
+		Template: &greetpb.GreetingTemplate{Name: *name, Text: fs.Arg(0)},
+	})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Id)
+}
+
+func doListTemplates(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	res, err := greetpb.NewGreetServiceClient(conn).ListTemplates(ctx, &greetpb.ListTemplatesRequest{})
+	if err != nil {
+		return err
+	}
+	if out.json {
+		return out.print(res, nil)
+	}
+	for _, t := range res.Templates {
+		if err := out.print(t, fmt.Sprintf("%s\t%s\t%s", t.Id, t.Name, t.Text)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
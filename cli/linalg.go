@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"grpc-udemy/calculator/calculatorpb"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// parseVector parses comma separated numbers, e.g. "1,2,3".
+func parseVector(s string) (*calculatorpb.Vector, error) {
+	v := &calculatorpb.Vector{}
+	for _, f := range strings.Split(s, ",") {
+		n, err := parseFloat(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		v.Values = append(v.Values, n)
+	}
+	return v, nil
+}
+
+// parseMatrix parses rows separated by semicolons, e.g. "1,2;3,4".
+func parseMatrix(s string) (*calculatorpb.Matrix, error) {
+	m := &calculatorpb.Matrix{}
+	for _, row := range strings.Split(s, ";") {
+		v, err := parseVector(row)
+		if err != nil {
+			return nil, err
+		}
+		if m.Rows > 0 && int(m.Cols) != len(v.Values) {
+			return nil, usageErrorf("row %d of %q has %d values, expected %d", m.Rows+1, s, len(v.Values), m.Cols)
+		}
+		m.Rows++
+		m.Cols = int32(len(v.Values))
+		m.Values = append(m.Values, v.Values...)
+	}
+	return m, nil
+}
+
+func formatVector(v *calculatorpb.Vector) string {
+	values := make([]string, len(v.GetValues()))
+	for i, n := range v.GetValues() {
+		values[i] = fmt.Sprint(n)
+	}
+	return strings.Join(values, " ")
+}
+
+func formatMatrix(m *calculatorpb.Matrix) string {
+	rows := make([]string, m.GetRows())
+	for i := range rows {
+		row := m.Values[i*int(m.Cols) : (i+1)*int(m.Cols)]
+		rows[i] = formatVector(&calculatorpb.Vector{Values: row})
+	}
+	return strings.Join(rows, "\n")
+}
+
+func matrixArgs(name string, n int, args []string) ([]*calculatorpb.Matrix, error) {
+	if len(args) != n {
+		return nil, usageErrorf("%s requires exactly %d matrices", name, n)
+	}
+	res := make([]*calculatorpb.Matrix, n)
+	for i, a := range args {
+		m, err := parseMatrix(a)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = m
+	}
+	return res, nil
+}
+
+func doDotProduct(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("dot requires exactly two vectors")
+	}
+	a, err := parseVector(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := parseVector(args[1])
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).DotProduct(ctx, &calculatorpb.DotProductRequest{A: a, B: b})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Result)
+}
+
+func doMatrixMultiply(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	m, err := matrixArgs("matmul", 2, args)
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).MatrixMultiply(ctx, &calculatorpb.MatrixMultiplyRequest{A: m[0], B: m[1]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatMatrix(res.Matrix))
+}
+
+func doTranspose(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	m, err := matrixArgs("transpose", 1, args)
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).Transpose(ctx, &calculatorpb.TransposeRequest{Matrix: m[0]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatMatrix(res.Matrix))
+}
+
+func doDeterminant(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	m, err := matrixArgs("det", 1, args)
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).Determinant(ctx, &calculatorpb.DeterminantRequest{Matrix: m[0]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, res.Determinant)
+}
+
+func doInverse(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	m, err := matrixArgs("inverse", 1, args)
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).Inverse(ctx, &calculatorpb.InverseRequest{Matrix: m[0]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatMatrix(res.Matrix))
+}
+
+func doSolveLinearSystem(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("solve requires a matrix and a vector")
+	}
+	a, err := parseMatrix(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := parseVector(args[1])
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).SolveLinearSystem(ctx, &calculatorpb.SolveLinearSystemRequest{A: a, B: b})
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatVector(res.X))
+}
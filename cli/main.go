@@ -0,0 +1,176 @@
+// Command cli is a command line client for greet, calculator and blog services.
+//
+// Usage:
+//
+//	cli [flags] <service> <method> [args]
+//
+// Exit code is the numeric gRPC status code of a failed call, 64 for usage errors.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"grpc-udemy/clientconfig"
+	"grpc-udemy/discovery"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const exitUsage = 64
+
+var (
+	addr     = flag.String("addr", "localhost:50051", "server address, comma separated list of addresses, dns:///name:port or file:///path/to/endpoints")
+	timeout  = flag.Duration("timeout", 0, "call timeout, 0 means no timeout")
+	useTLS   = flag.Bool("tls", false, "use TLS")
+	caFile   = flag.String("tls-ca", "", "CA certificate file, system roots are used if empty")
+	output   = flag.String("output", "text", "output format: text or json")
+	scFile   = flag.String("service-config", "", "service config JSON file merged into the defaults: its top-level fields, and its methodConfig entries for the called service, replace the default ones")
+	noCache  = flag.Bool("no-cache", false, "ask server to bypass its response cache")
+	language = flag.String("accept-language", "", "preferred languages sent as accept-language metadata, e.g. \"de-CH, fr;q=0.8\"")
+	services = map[string]map[string]command{
+		"greet": greetCommands,
+		"calc":  calcCommands,
+		"blog":  blogCommands,
+	}
+	serviceNames = map[string]string{
+		"greet": clientconfig.GreetService,
+		"calc":  clientconfig.CalculatorService,
+		"blog":  clientconfig.BlogService,
+	}
+)
+
+// command executes single method, args are command line arguments after method name.
+type command func(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error
+
+// usageError is returned by commands when invoked with invalid arguments.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <service> <method> [args]\n\nServices and methods:\n", os.Args[0])
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		methods := make([]string, 0, len(services[name]))
+		for m := range services[name] {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s: %s\n", name, strings.Join(methods, " "))
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func dial(service string) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if *useTLS {
+		if *caFile != "" {
+			c, err := credentials.NewClientTLSFromFile(*caFile, "")
+			if err != nil {
+				return nil, err
+			}
+			creds = c
+		} else {
+			creds = credentials.NewTLS(&tls.Config{})
+		}
+	}
+	sc, err := clientconfig.DialOption(serviceNames[service], *scFile)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Dial(discovery.Target(*addr), grpc.WithTransportCredentials(creds), sc)
+}
+
+func run() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		return usageErrorf("service and method are required")
+	}
+	methods, ok := services[flag.Arg(0)]
+	if !ok {
+		return usageErrorf("unknown service %q", flag.Arg(0))
+	}
+	cmd, ok := methods[flag.Arg(1)]
+	if !ok {
+		return usageErrorf("unknown method %q of service %q", flag.Arg(1), flag.Arg(0))
+	}
+	out, err := newPrinter(*output, os.Stdout)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	conn, err := dial(flag.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to create dial: %w", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if *noCache {
+		ctx = metadata.AppendToOutgoingContext(ctx, "cache-control", "no-cache")
+	}
+	if *language != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "accept-language", *language)
+	}
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	return cmd(ctx, conn, out, flag.Args()[2:])
+}
+
+// exitCode maps error to process exit code.
+func exitCode(err error) int {
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		return exitUsage
+	}
+	var serr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &serr) {
+		return int(serr.GRPCStatus().Code())
+	}
+	return int(codes.Unknown)
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if err := run(); err != nil {
+		if errors.As(err, &usageError{}) {
+			log.Printf("%v\n\n", err)
+			usage()
+		} else {
+			log.Println(err)
+		}
+		os.Exit(exitCode(err))
+	}
+}
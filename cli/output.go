@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// printer writes responses either as human readable text or as JSON lines.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case "text":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// print writes message m. In text mode only the value v is printed.
+func (p *printer) print(m proto.Message, v interface{}) error {
+	if p.json {
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, v)
+	return err
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"grpc-udemy/calculator/calculatorpb"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var sessionOperations = map[string]calculatorpb.SessionOperation{
+	"=": calculatorpb.SessionOperation_SESSION_SET,
+	"+": calculatorpb.SessionOperation_SESSION_ADD,
+	"-": calculatorpb.SessionOperation_SESSION_SUBTRACT,
+	"*": calculatorpb.SessionOperation_SESSION_MULTIPLY,
+	"/": calculatorpb.SessionOperation_SESSION_DIVIDE,
+	"^": calculatorpb.SessionOperation_SESSION_POWER,
+}
+
+// parseSessionCommand parses one of "store NAME", "recall NAME", "undo",
+// "show" or operator followed by number or variable name, e.g. "+ 5".
+func parseSessionCommand(line string) (*calculatorpb.SessionRequest, error) {
+	f := strings.Fields(line)
+	req := &calculatorpb.SessionRequest{}
+	switch {
+	case len(f) == 1 && f[0] == "show":
+	case len(f) == 1 && f[0] == "undo":
+		req.Command = &calculatorpb.SessionRequest_Undo{Undo: &calculatorpb.UndoCommand{}}
+	case len(f) == 2 && f[0] == "store":
+		req.Command = &calculatorpb.SessionRequest_Store{Store: &calculatorpb.StoreCommand{Name: f[1]}}
+	case len(f) == 2 && f[0] == "recall":
+		req.Command = &calculatorpb.SessionRequest_Recall{Recall: &calculatorpb.RecallCommand{Name: f[1]}}
+	case len(f) == 2 && sessionOperations[f[0]] != 0:
+		apply := &calculatorpb.ApplyCommand{Operation: sessionOperations[f[0]]}
+		if v, err := strconv.ParseFloat(f[1], 64); err == nil {
+			apply.Operand = &calculatorpb.ApplyCommand_Value{Value: v}
+		} else {
+			apply.Operand = &calculatorpb.ApplyCommand_Variable{Variable: f[1]}
+		}
+		req.Command = &calculatorpb.SessionRequest_Apply{Apply: apply}
+	default:
+		return nil, fmt.Errorf("invalid command %q", line)
+	}
+	return req, nil
+}
+
+func formatSession(res *calculatorpb.SessionResponse) string {
+	names := make([]string, 0, len(res.Variables))
+	for name := range res.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s := fmt.Sprintf("acc=%v", res.Accumulator)
+	for _, name := range names {
+		s += fmt.Sprintf(" %s=%v", name, res.Variables[name])
+	}
+	if res.Error != nil {
+		s += fmt.Sprintf(" error: %s", status.FromProto(res.Error).Message())
+	}
+	return s
+}
+
+// doSession reads commands from stdin, one per line, and prints session state
+// after every command.
+func doSession(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("session", flag.ContinueOnError)
+	id := fs.String("id", "", "id of session to resume")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	stream, err := calculatorpb.NewCalculatorClient(conn).Session(ctx)
+	if err != nil {
+		return err
+	}
+	// every request is answered by exactly one response
+	exchange := func(req *calculatorpb.SessionRequest) (*calculatorpb.SessionResponse, error) {
+		if err := stream.Send(req); err != nil {
+			_, err = stream.Recv()
+			return nil, err
+		}
+		return stream.Recv()
+	}
+
+	res, err := exchange(&calculatorpb.SessionRequest{SessionId: *id})
+	if err != nil {
+		return err
+	}
+	if !out.json {
+		fmt.Fprintf(os.Stderr, "session %s\n", res.SessionId)
+	}
+	if err := out.print(res, formatSession(res)); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		req, err := parseSessionCommand(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		res, err := exchange(req)
+		if err != nil {
+			return err
+		}
+		if err := out.print(res, formatSession(res)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return stream.CloseSend()
+}
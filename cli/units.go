@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"grpc-udemy/calculator/calculatorpb"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+var complexOperations = map[string]calculatorpb.ComplexOperation{
+	"add":  calculatorpb.ComplexOperation_COMPLEX_ADD,
+	"sub":  calculatorpb.ComplexOperation_COMPLEX_SUBTRACT,
+	"mul":  calculatorpb.ComplexOperation_COMPLEX_MULTIPLY,
+	"div":  calculatorpb.ComplexOperation_COMPLEX_DIVIDE,
+	"pow":  calculatorpb.ComplexOperation_COMPLEX_POWER,
+	"sqrt": calculatorpb.ComplexOperation_COMPLEX_SQRT,
+	"exp":  calculatorpb.ComplexOperation_COMPLEX_EXP,
+	"log":  calculatorpb.ComplexOperation_COMPLEX_LOG,
+	"conj": calculatorpb.ComplexOperation_COMPLEX_CONJUGATE,
+	"abs":  calculatorpb.ComplexOperation_COMPLEX_ABS,
+}
+
+var unitOperations = map[string]calculatorpb.UnitOperation{
+	"+": calculatorpb.UnitOperation_UNIT_ADD,
+	"-": calculatorpb.UnitOperation_UNIT_SUBTRACT,
+	"*": calculatorpb.UnitOperation_UNIT_MULTIPLY,
+	"/": calculatorpb.UnitOperation_UNIT_DIVIDE,
+}
+
+func parseComplex(s string) (*calculatorpb.Complex, error) {
+	c, err := strconv.ParseComplex(s, 128)
+	if err != nil {
+		return nil, usageErrorf("invalid complex number %q", s)
+	}
+	return &calculatorpb.Complex{Real: real(c), Imag: imag(c)}, nil
+}
+
+// doComplexCompute takes operation name and one or two complex numbers, e.g.
+// "mul 1+2i 3-i".
+func doComplexCompute(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return usageErrorf("complex requires operation and one or two numbers")
+	}
+	op, ok := complexOperations[args[0]]
+	if !ok {
+		return usageErrorf("unknown complex operation %q", args[0])
+	}
+	req := &calculatorpb.ComplexRequest{Operation: op}
+	var err error
+	if req.A, err = parseComplex(args[1]); err != nil {
+		return err
+	}
+	if len(args) == 3 {
+		if req.B, err = parseComplex(args[2]); err != nil {
+			return err
+		}
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).ComplexCompute(ctx, req)
+	if err != nil {
+		return err
+	}
+	c := complex(res.Result.GetReal(), res.Result.GetImag())
+	return out.print(res, strings.Trim(strconv.FormatComplex(c, 'g', -1, 128), "()"))
+}
+
+func parseQuantity(value, unit string) (*calculatorpb.Quantity, error) {
+	v, err := parseFloat(value)
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.Quantity{Value: v, Unit: unit}, nil
+}
+
+func formatQuantity(q *calculatorpb.Quantity) string {
+	return strings.TrimSpace(fmt.Sprint(q.GetValue(), " ", q.GetUnit()))
+}
+
+// doConvertUnits takes value, its unit and target unit, e.g. "36 km/h m/s".
+func doConvertUnits(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	if len(args) != 3 {
+		return usageErrorf("convert requires value, unit and target unit")
+	}
+	q, err := parseQuantity(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).ConvertUnits(ctx, &calculatorpb.ConvertUnitsRequest{Quantity: q, Unit: args[2]})
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatQuantity(res.Quantity))
+}
+
+// doUnitCompute takes two quantities and operator, e.g. "100 m / 9.58 s".
+func doUnitCompute(ctx context.Context, conn *grpc.ClientConn, out *printer, args []string) error {
+	fs := flag.NewFlagSet("units", flag.ContinueOnError)
+	to := fs.String("to", "", "unit of the result")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("%v", err)
+	}
+	args = fs.Args()
+	if len(args) != 5 {
+		return usageErrorf("units requires value, unit, operator, value and unit")
+	}
+	op, ok := unitOperations[args[2]]
+	if !ok {
+		return usageErrorf("unknown operator %q", args[2])
+	}
+	a, err := parseQuantity(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	b, err := parseQuantity(args[3], args[4])
+	if err != nil {
+		return err
+	}
+	res, err := calculatorpb.NewCalculatorClient(conn).UnitCompute(ctx, &calculatorpb.UnitComputeRequest{Operation: op, A: a, B: b, Unit: *to})
+	if err != nil {
+		return err
+	}
+	return out.print(res, formatQuantity(res.Result))
+}
@@ -0,0 +1,163 @@
+// Package clientconfig provides default gRPC service configs used by clients.
+//
+// Service config defines round robin load balancing across resolved backends,
+// retry policy for idempotent methods and per-method timeouts.
+// Only UNAVAILABLE errors are retried, with exponential backoff.
+// Hedging is not configured since grpc-go does not implement hedging policy.
+package clientconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+)
+
+// Fully qualified service names as defined in proto files.
+const (
+	GreetService      = "greet.GreetService"
+	CalculatorService = "calculator.Calculator"
+	BlogService       = "blog.BlogService"
+)
+
+var defaults = map[string]string{
+	GreetService: `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [{"service": "greet.GreetService", "method": "Greet"}],
+		"timeout": "5s"
+	}]
+}`,
+	CalculatorService: `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [
+			{"service": "calculator.Calculator", "method": "Sum"},
+			{"service": "calculator.Calculator", "method": "SquareRoot"}
+		],
+		"timeout": "2s",
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`,
+	BlogService: `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [{"service": "blog.BlogService", "method": "ReadBlog"}],
+		"timeout": "5s",
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}, {
+		"name": [
+			{"service": "blog.BlogService", "method": "CreateBlog"},
+			{"service": "blog.BlogService", "method": "UpdateBlog"},
+			{"service": "blog.BlogService", "method": "DeleteBlog"}
+		],
+		"timeout": "5s"
+	}]
+}`,
+}
+
+// Default returns default service config for service.
+func Default(service string) (string, error) {
+	sc, ok := defaults[service]
+	if !ok {
+		return "", fmt.Errorf("no service config for service %q", service)
+	}
+	return sc, nil
+}
+
+// Load returns default service config for service merged with service
+// config from file at path, or just the default when path is empty.
+//
+// Top-level fields of the file replace the same fields of the default and the
+// rest of the default, e.g. round robin load balancing, is kept. Method configs
+// of the file which name service replace method configs of the default, so one
+// file can hold method configs of several services; if none name service, the
+// default method configs are kept.
+func Load(service, path string) (string, error) {
+	sc, err := Default(service)
+	if err != nil || path == "" {
+		return sc, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service config: %w", err)
+	}
+	var file map[string]json.RawMessage
+	if err := json.Unmarshal(b, &file); err != nil {
+		return "", fmt.Errorf("service config %s is not valid JSON object: %w", path, err)
+	}
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(sc), &merged); err != nil {
+		return "", err
+	}
+	for field, v := range file {
+		if field == "methodConfig" {
+			v, err = methodConfigs(service, v)
+			if err != nil {
+				return "", fmt.Errorf("service config %s: %w", path, err)
+			}
+			if v == nil {
+				continue
+			}
+		}
+		merged[field] = v
+	}
+	b, err = json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// methodConfigs returns method configs from raw which name service, or nil
+// if there are none.
+func methodConfigs(service string, raw json.RawMessage) (json.RawMessage, error) {
+	var configs []json.RawMessage
+	if err := json.Unmarshal(raw, &configs); err != nil {
+		return nil, fmt.Errorf("methodConfig is not a list: %w", err)
+	}
+	var matching []json.RawMessage
+	for _, c := range configs {
+		var mc struct {
+			Name []struct {
+				Service string `json:"service"`
+			} `json:"name"`
+		}
+		if err := json.Unmarshal(c, &mc); err != nil {
+			return nil, fmt.Errorf("invalid methodConfig: %w", err)
+		}
+		for _, n := range mc.Name {
+			if n.Service == service {
+				matching = append(matching, c)
+				break
+			}
+		}
+	}
+	if len(matching) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(matching)
+}
+
+// DialOption returns dial option which applies service config loaded with Load.
+func DialOption(service, path string) (grpc.DialOption, error) {
+	sc, err := Load(service, path)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithDefaultServiceConfig(sc), nil
+}
@@ -0,0 +1,284 @@
+package clientconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/calculator/calculatorpb"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// flakyServer fails first failures calls with UNAVAILABLE and counts all calls.
+type flakyServer struct {
+	calculatorpb.UnimplementedCalculatorServer
+	blogpb.UnimplementedBlogServiceServer
+
+	failures int32
+	delay    time.Duration
+	calls    int32
+}
+
+func (s *flakyServer) call() error {
+	n := atomic.AddInt32(&s.calls, 1)
+	time.Sleep(s.delay)
+	if s.failures < 0 || n <= s.failures {
+		return status.Error(codes.Unavailable, "flaky server")
+	}
+	return nil
+}
+
+func (s *flakyServer) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+	return &calculatorpb.SumResponse{Sum: req.X + req.Y}, nil
+}
+
+func (s *flakyServer) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+	return &blogpb.ReadBlogResponse{Blog: &blogpb.Blog{Id: req.Id}}, nil
+}
+
+func (s *flakyServer) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+	return &blogpb.CreateBlogResponse{Blog: req.Blog}, nil
+}
+
+func dial(t *testing.T, srv *flakyServer, service, path string) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	calculatorpb.RegisterCalculatorServer(s, srv)
+	blogpb.RegisterBlogServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	sc, err := DialOption(service, path)
+	if err != nil {
+		t.Fatalf("failed to load service config: %v", err)
+	}
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		sc,
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	for _, service := range []string{GreetService, CalculatorService, BlogService} {
+		sc, err := DialOption(service, "")
+		if err != nil {
+			t.Fatalf("%s: %v", service, err)
+		}
+		conn, err := grpc.Dial("localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()), sc)
+		if err != nil {
+			t.Fatalf("%s: invalid service config: %v", service, err)
+		}
+		conn.Close()
+	}
+}
+
+func TestDefaultUnknownService(t *testing.T) {
+	if _, err := Default("unknown.Service"); err == nil {
+		t.Fatal("expected error for unknown service")
+	}
+}
+
+func TestSumIsRetried(t *testing.T) {
+	srv := &flakyServer{failures: 2}
+	c := calculatorpb.NewCalculatorClient(dial(t, srv, CalculatorService, ""))
+
+	res, err := c.Sum(context.Background(), &calculatorpb.SumRequest{X: 3, Y: 5})
+	if err != nil {
+		t.Fatalf("expected sum to succeed after retries: %v", err)
+	}
+	if res.Sum != 8 {
+		t.Errorf("got sum %d, want 8", res.Sum)
+	}
+	if calls := atomic.LoadInt32(&srv.calls); calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
+
+func TestReadBlogRetriesExhausted(t *testing.T) {
+	srv := &flakyServer{failures: -1}
+	c := blogpb.NewBlogServiceClient(dial(t, srv, BlogService, ""))
+
+	_, err := c.ReadBlog(context.Background(), &blogpb.ReadBlogRequest{Id: "1"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("got %v, want UNAVAILABLE", err)
+	}
+	if calls := atomic.LoadInt32(&srv.calls); calls != 4 {
+		t.Errorf("got %d calls, want 4", calls)
+	}
+}
+
+func TestCreateBlogIsNotRetried(t *testing.T) {
+	srv := &flakyServer{failures: 1}
+	c := blogpb.NewBlogServiceClient(dial(t, srv, BlogService, ""))
+
+	_, err := c.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: &blogpb.Blog{}})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("got %v, want UNAVAILABLE", err)
+	}
+	if calls := atomic.LoadInt32(&srv.calls); calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+}
+
+func TestOverrideFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calculator.json")
+	sc := `{
+	"methodConfig": [{
+		"name": [{"service": "calculator.Calculator", "method": "Sum"}],
+		"timeout": "0.2s",
+		"retryPolicy": {
+			"maxAttempts": 2,
+			"initialBackoff": "0.01s",
+			"maxBackoff": "0.01s",
+			"backoffMultiplier": 1,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+	if err := os.WriteFile(path, []byte(sc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		srv := &flakyServer{failures: -1}
+		c := calculatorpb.NewCalculatorClient(dial(t, srv, CalculatorService, path))
+
+		_, err := c.Sum(context.Background(), &calculatorpb.SumRequest{})
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("got %v, want UNAVAILABLE", err)
+		}
+		if calls := atomic.LoadInt32(&srv.calls); calls != 2 {
+			t.Errorf("got %d calls, want 2", calls)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		srv := &flakyServer{delay: time.Second}
+		c := calculatorpb.NewCalculatorClient(dial(t, srv, CalculatorService, path))
+
+		_, err := c.Sum(context.Background(), &calculatorpb.SumRequest{})
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("got %v, want DEADLINE_EXCEEDED", err)
+		}
+	})
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(CalculatorService, path); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+	if err := os.WriteFile(path, []byte(`{"methodConfig": {}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(CalculatorService, path); err == nil {
+		t.Fatal("expected error for methodConfig which is not a list")
+	}
+	if _, err := Load(CalculatorService, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestLoadMergesDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service-config.json")
+	sc := `{
+	"methodConfig": [{
+		"name": [{"service": "calculator.Calculator", "method": "Sum"}],
+		"timeout": "0.2s"
+	}, {
+		"name": [{"service": "blog.BlogService"}],
+		"timeout": "1s"
+	}]
+}`
+	if err := os.WriteFile(path, []byte(sc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lbPath := filepath.Join(t.TempDir(), "pick-first.json")
+	if err := os.WriteFile(lbPath, []byte(`{"loadBalancingConfig": [{"pick_first": {}}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// decode returns top-level fields of sc as compact JSON
+	decode := func(sc string) map[string]string {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(sc), &fields); err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]string{}
+		for k, v := range fields {
+			b, _ := json.Marshal(v)
+			m[k] = string(b)
+		}
+		return m
+	}
+	tests := []struct {
+		service, path string
+		lb            string
+		timeouts      []string
+	}{
+		{CalculatorService, path, `[{"round_robin":{}}]`, []string{"0.2s"}},
+		{BlogService, path, `[{"round_robin":{}}]`, []string{"1s"}},
+		// no method config names greet, so the default is kept
+		{GreetService, path, `[{"round_robin":{}}]`, []string{"5s"}},
+		{BlogService, lbPath, `[{"pick_first":{}}]`, []string{"5s", "5s"}},
+	}
+	for _, tt := range tests {
+		got, err := Load(tt.service, tt.path)
+		if err != nil {
+			t.Fatalf("Load(%s, %s): %v", tt.service, filepath.Base(tt.path), err)
+		}
+		fields := decode(got)
+		if fields["loadBalancingConfig"] != tt.lb {
+			t.Errorf("Load(%s, %s) loadBalancingConfig = %s, want %s", tt.service, filepath.Base(tt.path), fields["loadBalancingConfig"], tt.lb)
+		}
+		var mcs []struct{ Timeout string }
+		if err := json.Unmarshal([]byte(fields["methodConfig"]), &mcs); err != nil {
+			t.Fatal(err)
+		}
+		var timeouts []string
+		for _, mc := range mcs {
+			timeouts = append(timeouts, mc.Timeout)
+		}
+		if fmt.Sprint(timeouts) != fmt.Sprint(tt.timeouts) {
+			t.Errorf("Load(%s, %s) method timeouts = %v, want %v", tt.service, filepath.Base(tt.path), timeouts, tt.timeouts)
+		}
+		conn, err := grpc.Dial("localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithDefaultServiceConfig(got))
+		if err != nil {
+			t.Fatalf("Load(%s, %s): invalid service config: %v", tt.service, filepath.Base(tt.path), err)
+		}
+		conn.Close()
+	}
+}
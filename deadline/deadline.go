@@ -0,0 +1,123 @@
+// Package deadline enforces server side deadlines. Calls without deadline get
+// a default one, deadlines longer than the allowed maximum are shortened, and
+// calls whose deadline already passed are rejected before reaching handlers.
+// Failed calls are logged together with the time left until their deadline.
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Limit bounds deadline of a method. Zero values disable the corresponding
+// bound.
+type Limit struct {
+	// Default is deadline of calls which come without one, bounded by Max.
+	Default time.Duration
+	// Max is the longest deadline allowed.
+	Max time.Duration
+}
+
+// Config holds limits of unary and streaming methods, Methods overrides them
+// for single methods by full method name.
+type Config struct {
+	Unary   Limit
+	Stream  Limit
+	Methods map[string]Limit
+}
+
+func (c Config) limit(method string, stream bool) Limit {
+	if l, ok := c.Methods[method]; ok {
+		return l
+	}
+	if stream {
+		return c.Stream
+	}
+	return c.Unary
+}
+
+// apply returns ctx with deadline bounded by l.
+func (l Limit) apply(ctx context.Context) (context.Context, context.CancelFunc) {
+	d, ok := ctx.Deadline()
+	switch {
+	case !ok && l.Default > 0:
+		if l.Max > 0 && l.Default > l.Max {
+			return context.WithTimeout(ctx, l.Max)
+		}
+		return context.WithTimeout(ctx, l.Default)
+	case l.Max > 0 && (!ok || time.Until(d) > l.Max):
+		return context.WithTimeout(ctx, l.Max)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Remaining describes time left until deadline of ctx, for logs.
+func Remaining(ctx context.Context) string {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return "no deadline"
+	}
+	left := time.Until(d)
+	if left <= 0 {
+		return "deadline exceeded"
+	}
+	return fmt.Sprintf("%v left", left.Round(time.Millisecond))
+}
+
+// Logf logs message followed by time left until deadline of ctx.
+func Logf(ctx context.Context, format string, a ...interface{}) {
+	log.Printf("%s (%s)", fmt.Sprintf(format, a...), Remaining(ctx))
+}
+
+func logFailure(ctx context.Context, method string, err error) {
+	if err != nil {
+		Logf(ctx, "%s failed with %v: %s", method, status.Code(err), status.Convert(err).Message())
+	}
+}
+
+// UnaryServerInterceptor bounds deadlines of unary calls by c.
+func UnaryServerInterceptor(c Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := c.limit(info.FullMethod, false).apply(ctx)
+		defer cancel()
+		if err := ctx.Err(); err != nil {
+			err = status.FromContextError(err).Err()
+			logFailure(ctx, info.FullMethod, err)
+			return nil, err
+		}
+		res, err := handler(ctx, req)
+		logFailure(ctx, info.FullMethod, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor bounds deadlines of streaming calls by c.
+func StreamServerInterceptor(c Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := c.limit(info.FullMethod, true).apply(ss.Context())
+		defer cancel()
+		if err := ctx.Err(); err != nil {
+			err = status.FromContextError(err).Err()
+			logFailure(ctx, info.FullMethod, err)
+			return err
+		}
+		err := handler(srv, &stream{ServerStream: ss, ctx: ctx})
+		logFailure(ctx, info.FullMethod, err)
+		return err
+	}
+}
+
+// stream replaces context of server stream.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,92 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLimitApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  Limit
+		client time.Duration // 0 means no client deadline
+		want   time.Duration // 0 means no deadline
+	}{
+		{"no limits", Limit{}, 0, 0},
+		{"default", Limit{Default: time.Second}, 0, time.Second},
+		{"max without deadline", Limit{Max: time.Minute}, 0, time.Minute},
+		{"default longer than max", Limit{Default: time.Hour, Max: time.Minute}, 0, time.Minute},
+		{"client shorter", Limit{Default: time.Second, Max: time.Minute}, 10 * time.Second, 10 * time.Second},
+		{"client longer", Limit{Max: time.Minute}, time.Hour, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.client > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.client)
+				defer cancel()
+			}
+			ctx, cancel := tt.limit.apply(ctx)
+			defer cancel()
+			d, ok := ctx.Deadline()
+			if tt.want == 0 {
+				if ok {
+					t.Fatalf("got deadline in %v, want none", time.Until(d))
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("got no deadline, want %v", tt.want)
+			}
+			if left := time.Until(d); left > tt.want || left < tt.want-time.Second {
+				t.Errorf("got deadline in %v, want %v", left, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigLimit(t *testing.T) {
+	c := Config{
+		Unary:   Limit{Default: time.Second},
+		Stream:  Limit{Max: time.Minute},
+		Methods: map[string]Limit{"/s/Long": {}},
+	}
+	if got := c.limit("/s/Unary", false); got != c.Unary {
+		t.Errorf("unary limit = %v", got)
+	}
+	if got := c.limit("/s/Stream", true); got != c.Stream {
+		t.Errorf("stream limit = %v", got)
+	}
+	if got := c.limit("/s/Long", false); got != (Limit{}) {
+		t.Errorf("overridden limit = %v", got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := UnaryServerInterceptor(Config{Unary: Limit{Default: 10 * time.Millisecond}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/s/M"}
+	_, err := intercept(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("got %v, want DEADLINE_EXCEEDED", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	_, err = intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	if called || status.Code(err) != codes.Canceled {
+		t.Errorf("canceled call reached handler %v, error %v", called, err)
+	}
+}
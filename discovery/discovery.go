@@ -0,0 +1,75 @@
+// Package discovery provides gRPC resolvers used by clients to find server
+// instances, so calls can be load balanced across multiple backends.
+//
+// Importing the package registers following schemes:
+//
+//	static:///host1:50051,host2:50051   fixed list of backends
+//	file:///path/to/endpoints           backends read from file, reloaded on change
+//
+// DNS names are resolved by grpc built-in dns:/// scheme.
+package discovery
+
+import (
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// StaticScheme is scheme of resolver which returns fixed list of addresses.
+	StaticScheme = "static"
+	// FileScheme is scheme of resolver which reads addresses from file.
+	FileScheme = "file"
+
+	// DefaultPollInterval is how often file resolver checks endpoints file for changes.
+	DefaultPollInterval = time.Second
+)
+
+func init() {
+	resolver.Register(&staticBuilder{})
+	resolver.Register(NewFileBuilder(DefaultPollInterval))
+}
+
+// Target converts address given by user into dial target.
+// Comma separated list of addresses is turned into static target,
+// anything else (single address or target with scheme) is returned unchanged.
+func Target(addr string) string {
+	if strings.Contains(addr, "://") || !strings.Contains(addr, ",") {
+		return addr
+	}
+	return StaticScheme + ":///" + addr
+}
+
+// parseAddresses splits list of addresses separated by commas or new lines,
+// ignoring empty lines and lines starting with #.
+func parseAddresses(s string) []resolver.Address {
+	addrs := make([]resolver.Address, 0)
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addrs = append(addrs, resolver.Address{Addr: line})
+	}
+	return addrs
+}
+
+type staticBuilder struct{}
+
+func (b *staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	if err := cc.UpdateState(resolver.State{Addresses: parseAddresses(target.Endpoint)}); err != nil {
+		return nil, err
+	}
+	return &staticResolver{}, nil
+}
+
+func (b *staticBuilder) Scheme() string {
+	return StaticScheme
+}
+
+type staticResolver struct{}
+
+func (r *staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *staticResolver) Close() {}
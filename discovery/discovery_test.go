@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"context"
+	"grpc-udemy/clientconfig"
+	"grpc-udemy/greet/greetpb"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// namedServer answers every greeting with its own name.
+type namedServer struct {
+	greetpb.UnimplementedGreetServiceServer
+	name string
+}
+
+func (s *namedServer) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	return &greetpb.GreetResponse{Result: s.name}, nil
+}
+
+func startServer(t *testing.T, name string) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	greetpb.RegisterGreetServiceServer(s, &namedServer{name: name})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func dial(t *testing.T, target string, opts ...grpc.DialOption) greetpb.GreetServiceClient {
+	t.Helper()
+
+	sc, err := clientconfig.DialOption(clientconfig.GreetService, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()), sc)
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		t.Fatalf("failed to dial %s: %v", target, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return greetpb.NewGreetServiceClient(conn)
+}
+
+// backends calls Greet n times and returns names of servers which answered.
+func backends(t *testing.T, c greetpb.GreetServiceClient, n int) map[string]int {
+	t.Helper()
+
+	res := make(map[string]int)
+	for i := 0; i < n; i++ {
+		r, err := c.Greet(context.Background(), &greetpb.GreetRequest{Greeting: &greetpb.Greeting{}})
+		if err != nil {
+			t.Fatalf("failed to greet: %v", err)
+		}
+		res[r.Result]++
+	}
+	return res
+}
+
+// waitForBackend fails the test if no call reaches backend name within few seconds.
+func waitForBackend(t *testing.T, c greetpb.GreetServiceClient, name string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if got := backends(t, c, 4); got[name] > 0 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("no call reached backend %s", name)
+}
+
+func TestTarget(t *testing.T) {
+	tests := map[string]string{
+		"localhost:50051":            "localhost:50051",
+		"a:1,b:2":                    "static:///a:1,b:2",
+		"dns:///calculator:50051":    "dns:///calculator:50051",
+		"file:///etc/blog/endpoints": "file:///etc/blog/endpoints",
+		"static:///localhost:1,b:2":  "static:///localhost:1,b:2",
+	}
+	for in, want := range tests {
+		if got := Target(in); got != want {
+			t.Errorf("Target(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStaticRoundRobin(t *testing.T) {
+	a := startServer(t, "a")
+	b := startServer(t, "b")
+	c := dial(t, Target(a+","+b))
+
+	waitForBackend(t, c, "a")
+	waitForBackend(t, c, "b")
+}
+
+func TestFileResolverReload(t *testing.T) {
+	a := startServer(t, "a")
+	b := startServer(t, "b")
+
+	path := filepath.Join(t.TempDir(), "endpoints")
+	if err := os.WriteFile(path, []byte("# greet servers\n"+a+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := dial(t, "file://"+path, grpc.WithResolvers(NewFileBuilder(10*time.Millisecond)))
+	if got := backends(t, c, 4); got["a"] != 4 {
+		t.Fatalf("expected all calls to reach a: %v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join([]string{a, b, ""}, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForBackend(t, c, "b")
+}
+
+func TestFileResolverMissingFile(t *testing.T) {
+	b := NewFileBuilder(time.Second)
+	_, err := grpc.Dial("file://"+filepath.Join(t.TempDir(), "missing"),
+		grpc.WithResolvers(b),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err == nil {
+		t.Fatal("expected dial to fail for missing endpoints file")
+	}
+}
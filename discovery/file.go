@@ -0,0 +1,114 @@
+package discovery
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// NewFileBuilder creates builder of resolvers which read addresses from file,
+// one address per line, and check the file for changes every interval.
+func NewFileBuilder(interval time.Duration) resolver.Builder {
+	return &fileBuilder{interval: interval}
+}
+
+type fileBuilder struct {
+	interval time.Duration
+}
+
+func (b *fileBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	path := target.URL.Path
+	if path == "" {
+		path = target.Endpoint
+	}
+
+	r := &fileResolver{
+		path: path,
+		cc:   cc,
+		now:  make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	r.wg.Add(1)
+	go r.watch(b.interval)
+	return r, nil
+}
+
+func (b *fileBuilder) Scheme() string {
+	return FileScheme
+}
+
+type fileResolver struct {
+	path string
+	cc   resolver.ClientConn
+
+	modTime time.Time
+	size    int64
+
+	now  chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// reload reads the file and updates client connection if the file changed.
+func (r *fileResolver) reload() error {
+	fi, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat endpoints file: %w", err)
+	}
+	if fi.ModTime().Equal(r.modTime) && fi.Size() == r.size {
+		return nil
+	}
+
+	b, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read endpoints file: %w", err)
+	}
+	addrs := parseAddresses(string(b))
+	if len(addrs) == 0 {
+		return fmt.Errorf("no endpoints in file %s", r.path)
+	}
+
+	r.modTime = fi.ModTime()
+	r.size = fi.Size()
+	log.Printf("resolved %d endpoints from %s", len(addrs), r.path)
+	return r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+func (r *fileResolver) watch(interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.now:
+		}
+		if err := r.reload(); err != nil {
+			r.cc.ReportError(err)
+		}
+	}
+}
+
+func (r *fileResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *fileResolver) Close() {
+	close(r.done)
+	r.wg.Wait()
+}
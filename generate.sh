@@ -1,5 +1,29 @@
 #!/bin/bash
+# Usage: ./generate.sh [service...]
+# Regenerates the given services (greet, calculator, blog), all of them by
+# default, so a change to one proto leaves generated code of others alone.
 
-protoc greet/greetpb/greet.proto --go_out=. --go-grpc_out=.
-protoc calculator/calculatorpb/calculator.proto --go_out=. --go-grpc_out=.
-protoc blog/blogpb/blog.proto --go_out=. --go-grpc_out=.
\ No newline at end of file
+PROTO_PATH="-I . -I third_party/googleapis"
+
+services=("$@")
+if [ ${#services[@]} -eq 0 ]; then
+    services=(greet calculator blog)
+fi
+
+for service in "${services[@]}"; do
+    case $service in
+    greet)
+        protoc $PROTO_PATH greet/greetpb/greet.proto --go_out=. --go-grpc_out=.
+        ;;
+    calculator)
+        protoc $PROTO_PATH calculator/calculatorpb/calculator.proto --go_out=. --go-grpc_out=. --grpc-gateway_out=.
+        ;;
+    blog)
+        protoc $PROTO_PATH blog/blogpb/blog.proto --go_out=. --go-grpc_out=. --grpc-gateway_out=.
+        ;;
+    *)
+        echo "unknown service $service" >&2
+        exit 1
+        ;;
+    esac || exit 1
+done
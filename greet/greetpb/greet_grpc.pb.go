@@ -20,14 +20,23 @@ const _ = grpc.SupportPackageIsVersion7
 type GreetServiceClient interface {
 	// Unary
 	Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error)
-	// Server streaming
+	// Server streaming, every response carries token resuming the stream
+	// after it. This RPC throw an INVALID_ARGUMENT if resume_token is malformed
 	GreetManyTimes(ctx context.Context, in *GreeetManyTimesRequest, opts ...grpc.CallOption) (GreetService_GreetManyTimesClient, error)
 	// Client streaming
 	LongGreet(ctx context.Context, opts ...grpc.CallOption) (GreetService_LongGreetClient, error)
-	// Bidirectional streaming
+	// Bidirectional streaming, greetings are broadcast to all participants of
+	// the room; participant is named by the first greeting of its stream.
+	// Slow participants lose messages or are disconnected with
+	// RESOURCE_EXHAUSTED depending on server policy
 	GreetEveryone(ctx context.Context, opts ...grpc.CallOption) (GreetService_GreetEveryoneClient, error)
-	// Deadline greet
+	// Deadline greet, takes 3 seconds. This RPC throw a DEADLINE_EXCEEDED right
+	// away if deadline of the call is shorter
 	GreetWithDeadline(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error)
+	// Templates
+	// this RPC throw an INVALID_ARGUMENT for unknown placeholders
+	CreateTemplate(ctx context.Context, in *CreateTemplateRequest, opts ...grpc.CallOption) (*GreetingTemplate, error)
+	ListTemplates(ctx context.Context, in *ListTemplatesRequest, opts ...grpc.CallOption) (*ListTemplatesResponse, error)
 }
 
 type greetServiceClient struct {
@@ -153,20 +162,47 @@ func (c *greetServiceClient) GreetWithDeadline(ctx context.Context, in *GreetReq
 	return out, nil
 }
 
+func (c *greetServiceClient) CreateTemplate(ctx context.Context, in *CreateTemplateRequest, opts ...grpc.CallOption) (*GreetingTemplate, error) {
+	out := new(GreetingTemplate)
+	err := c.cc.Invoke(ctx, "/greet.GreetService/CreateTemplate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greetServiceClient) ListTemplates(ctx context.Context, in *ListTemplatesRequest, opts ...grpc.CallOption) (*ListTemplatesResponse, error) {
+	out := new(ListTemplatesResponse)
+	err := c.cc.Invoke(ctx, "/greet.GreetService/ListTemplates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GreetServiceServer is the server API for GreetService service.
 // All implementations must embed UnimplementedGreetServiceServer
 // for forward compatibility
 type GreetServiceServer interface {
 	// Unary
 	Greet(context.Context, *GreetRequest) (*GreetResponse, error)
-	// Server streaming
+	// Server streaming, every response carries token resuming the stream
+	// after it. This RPC throw an INVALID_ARGUMENT if resume_token is malformed
 	GreetManyTimes(*GreeetManyTimesRequest, GreetService_GreetManyTimesServer) error
 	// Client streaming
 	LongGreet(GreetService_LongGreetServer) error
-	// Bidirectional streaming
+	// Bidirectional streaming, greetings are broadcast to all participants of
+	// the room; participant is named by the first greeting of its stream.
+	// Slow participants lose messages or are disconnected with
+	// RESOURCE_EXHAUSTED depending on server policy
 	GreetEveryone(GreetService_GreetEveryoneServer) error
-	// Deadline greet
+	// Deadline greet, takes 3 seconds. This RPC throw a DEADLINE_EXCEEDED right
+	// away if deadline of the call is shorter
 	GreetWithDeadline(context.Context, *GreetRequest) (*GreetResponse, error)
+	// Templates
+	// this RPC throw an INVALID_ARGUMENT for unknown placeholders
+	CreateTemplate(context.Context, *CreateTemplateRequest) (*GreetingTemplate, error)
+	ListTemplates(context.Context, *ListTemplatesRequest) (*ListTemplatesResponse, error)
 	mustEmbedUnimplementedGreetServiceServer()
 }
 
@@ -189,6 +225,12 @@ func (UnimplementedGreetServiceServer) GreetEveryone(GreetService_GreetEveryoneS
 func (UnimplementedGreetServiceServer) GreetWithDeadline(context.Context, *GreetRequest) (*GreetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GreetWithDeadline not implemented")
 }
+func (UnimplementedGreetServiceServer) CreateTemplate(context.Context, *CreateTemplateRequest) (*GreetingTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTemplate not implemented")
+}
+func (UnimplementedGreetServiceServer) ListTemplates(context.Context, *ListTemplatesRequest) (*ListTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTemplates not implemented")
+}
 func (UnimplementedGreetServiceServer) mustEmbedUnimplementedGreetServiceServer() {}
 
 // UnsafeGreetServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -311,6 +353,42 @@ func _GreetService_GreetWithDeadline_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GreetService_CreateTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreetServiceServer).CreateTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greet.GreetService/CreateTemplate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreetServiceServer).CreateTemplate(ctx, req.(*CreateTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GreetService_ListTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreetServiceServer).ListTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greet.GreetService/ListTemplates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreetServiceServer).ListTemplates(ctx, req.(*ListTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GreetService_ServiceDesc is the grpc.ServiceDesc for GreetService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -326,6 +404,14 @@ var GreetService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GreetWithDeadline",
 			Handler:    _GreetService_GreetWithDeadline_Handler,
 		},
+		{
+			MethodName: "CreateTemplate",
+			Handler:    _GreetService_CreateTemplate_Handler,
+		},
+		{
+			MethodName: "ListTemplates",
+			Handler:    _GreetService_ListTemplates_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
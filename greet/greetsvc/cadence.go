@@ -0,0 +1,63 @@
+package greetsvc
+
+import (
+	"grpc-udemy/streaming"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	defaultCount    = 10
+	maxCount        = 1000
+	defaultInterval = time.Second
+	maxInterval     = 10 * time.Second
+
+	// greetManyKind names GreetManyTimes resume tokens.
+	greetManyKind = "greet-many"
+)
+
+// greetCount returns number of greetings to send, 0 means default.
+func greetCount(n int32) (int, error) {
+	switch {
+	case n == 0:
+		return defaultCount, nil
+	case n < 0 || n > maxCount:
+		return 0, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d, got %d", maxCount, n)
+	}
+	return int(n), nil
+}
+
+// greetInterval returns delay between greetings, nil means default.
+func greetInterval(d *durationpb.Duration) (time.Duration, error) {
+	if d == nil {
+		return defaultInterval, nil
+	}
+	if err := d.CheckValid(); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid interval: %v", err)
+	}
+	if v := d.AsDuration(); v < 0 || v > maxInterval {
+		return 0, status.Errorf(codes.InvalidArgument, "interval must be between 0 and %v, got %v", maxInterval, v)
+	}
+	return d.AsDuration(), nil
+}
+
+// greetResumePosition returns index of the first greeting to send, token is
+// resume token of the last greeting received by the client.
+func greetResumePosition(token string, count int) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	position, err := streaming.ParseResumeToken(greetManyKind, token)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(position)
+	if err != nil || n < 0 || n > count {
+		return 0, status.Errorf(codes.InvalidArgument, "resume token does not match count %d", count)
+	}
+	return n, nil
+}
@@ -0,0 +1,26 @@
+package greetsvc
+
+import (
+	"grpc-udemy/deadline"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// greetDelay is how long GreetWithDeadline works on a greeting.
+const greetDelay = 3 * time.Second
+
+// ServerOptions returns server options enforcing deadlines. Streams are not
+// limited, greeting rooms in particular stay open as long as clients want.
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	c := deadline.Config{
+		Unary: s.deadline,
+		Methods: map[string]deadline.Limit{
+			"/greet.GreetService/GreetWithDeadline": {Default: 2 * greetDelay, Max: 4 * greetDelay},
+		},
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(deadline.UnaryServerInterceptor(c)),
+		grpc.ChainStreamInterceptor(deadline.StreamServerInterceptor(c)),
+	}
+}
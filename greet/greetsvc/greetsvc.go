@@ -0,0 +1,219 @@
+// Package greetsvc implements GreetService. Greetings are localized and
+// personalized by templates, GreetEveryone streams meet in rooms.
+package greetsvc
+
+import (
+	"context"
+	"grpc-udemy/deadline"
+	"grpc-udemy/greet/greetpb"
+	"grpc-udemy/greet/i18n"
+	"grpc-udemy/streaming"
+	"io"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config configures Server, zero values select defaults.
+type Config struct {
+	// RoomBuffer is number of messages buffered for each GreetEveryone
+	// participant.
+	RoomBuffer   int
+	SlowConsumer SlowConsumerPolicy
+	// Deadline bounds deadlines of unary calls.
+	Deadline deadline.Limit
+	// Now and Sleep replace the real clock, mainly in tests.
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Server implements greetpb.GreetServiceServer.
+type Server struct {
+	greetpb.UnimplementedGreetServiceServer
+	templates *templateStore
+	rooms     *hub
+	deadline  deadline.Limit
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// New creates Server configured by cfg.
+func New(cfg Config) *Server {
+	if cfg.RoomBuffer < 1 {
+		cfg.RoomBuffer = defaultRoomBuffer
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Sleep == nil {
+		cfg.Sleep = streaming.Sleep
+	}
+	return &Server{
+		templates: newTemplateStore(),
+		rooms:     newHub(cfg.RoomBuffer, cfg.SlowConsumer),
+		deadline:  cfg.Deadline,
+		now:       cfg.Now,
+		sleep:     cfg.Sleep,
+	}
+}
+
+func (s *Server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	l := locale(ctx, req.Greeting)
+	grpc.SetHeader(ctx, languageHeader(l))
+	result, err := s.greeting(req.Greeting, l, i18n.Greet)
+	if err != nil {
+		return nil, err
+	}
+	res := &greetpb.GreetResponse{
+		Result: result,
+	}
+	return res, nil
+}
+
+func (s *Server) GreetManyTimes(req *greetpb.GreeetManyTimesRequest, stream greetpb.GreetService_GreetManyTimesServer) error {
+	count, err := greetCount(req.Count)
+	if err != nil {
+		return err
+	}
+	interval, err := greetInterval(req.Interval)
+	if err != nil {
+		return err
+	}
+	l := locale(stream.Context(), req.Greeting)
+	stream.SetHeader(languageHeader(l))
+	greeting, err := s.greeting(req.Greeting, l, i18n.Greet)
+	if err != nil {
+		return err
+	}
+	start, err := greetResumePosition(req.ResumeToken, count)
+	if err != nil {
+		return err
+	}
+	for i := start; i < count; i++ {
+		if i > start {
+			if err := s.sleep(stream.Context(), interval); err != nil {
+				return err
+			}
+		}
+		result := i18n.Default.Format(l, i18n.GreetMany, count, map[string]string{"greeting": greeting, "index": strconv.Itoa(i + 1)})
+		res := &greetpb.GreetManyTimesResponse{
+			Result:      result,
+			ResumeToken: streaming.ResumeToken(greetManyKind, strconv.Itoa(i+1)),
+		}
+		if err := streaming.Send(stream, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LongGreet greets all received names at once, in locale of the first
+// greeting which sets one.
+func (s *Server) LongGreet(stream greetpb.GreetService_LongGreetServer) error {
+	var names []string
+	var tag string
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			l := locale(stream.Context(), &greetpb.Greeting{Locale: tag})
+			stream.SetHeader(languageHeader(l))
+			result := i18n.Default.Format(l, i18n.GreetAll, len(names), map[string]string{"names": i18n.Default.JoinList(l, names)})
+			return stream.SendAndClose(&greetpb.LongGreetResponse{
+				Result: result,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		if tag == "" {
+			tag = req.GetGreeting().GetLocale()
+		}
+		names = append(names, displayName(req.Greeting))
+	}
+}
+
+// GreetEveryone joins the stream to a room, every greeting is rendered in
+// locale of its sender and broadcast to the whole room.
+//
+// The stream is received until the handler returns, so participant which
+// left the room, e.g. disconnected as slow consumer, gets its error when it
+// sends next greeting or closes the stream.
+func (s *Server) GreetEveryone(stream greetpb.GreetService_GreetEveryoneServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	room := req.Room
+	if room == "" {
+		room = defaultRoom
+	}
+	m := s.rooms.join(room, displayName(req.Greeting))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			l := locale(stream.Context(), req.Greeting)
+			result, err := s.greeting(req.Greeting, l, i18n.GreetShort)
+			if err != nil {
+				s.rooms.leave(m, err)
+				return
+			}
+			if !s.rooms.publish(m, result) {
+				return
+			}
+			req, err = stream.Recv()
+			if err != nil {
+				// io.EOF ends the stream once queued messages are sent
+				if err == io.EOF {
+					err = nil
+				}
+				s.rooms.leave(m, err)
+				return
+			}
+		}
+	}()
+
+	// stream must not be used after the handler returns, so every return
+	// waits for the goroutine; once m left the room, it stops after Recv
+	// returns with the next message or close of the client, or fails with
+	// the broken stream
+	for msg := range m.ch {
+		if err := streaming.Send(stream, msg); err != nil {
+			s.rooms.leave(m, err)
+			for range m.ch {
+			}
+			<-done
+			return err
+		}
+	}
+	<-done
+	return m.err
+}
+
+// GreetWithDeadline greets after greetDelay. Calls whose deadline is too
+// short fail right away instead of waiting for it. Time left is measured by
+// the same clock which sleeps.
+func (s *Server) GreetWithDeadline(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	if d, ok := ctx.Deadline(); ok && d.Sub(s.now()) < greetDelay {
+		return nil, status.Errorf(codes.DeadlineExceeded, "greeting takes %v", greetDelay)
+	}
+	if err := s.sleep(ctx, greetDelay); err != nil {
+		return nil, err
+	}
+	return s.Greet(ctx, req)
+}
+
+func (s *Server) CreateTemplate(ctx context.Context, req *greetpb.CreateTemplateRequest) (*greetpb.GreetingTemplate, error) {
+	return s.templates.create(req.Template)
+}
+
+func (s *Server) ListTemplates(ctx context.Context, req *greetpb.ListTemplatesRequest) (*greetpb.ListTemplatesResponse, error) {
+	return &greetpb.ListTemplatesResponse{Templates: s.templates.list()}, nil
+}
@@ -0,0 +1,579 @@
+package greetsvc_test
+
+import (
+	"context"
+	"fmt"
+	"grpc-udemy/greet/greetpb"
+	"grpc-udemy/greet/greetsvc"
+	"grpc-udemy/grpctest"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// morning is 08:00 UTC.
+var morning = time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+
+func newClient(t *testing.T) (greetpb.GreetServiceClient, *grpctest.Clock) {
+	clock := grpctest.NewClock(morning)
+	return grpctest.Greet(t, greetsvc.Config{Now: clock.Now, Sleep: clock.Sleep}), clock
+}
+
+func TestGreet(t *testing.T) {
+	client, _ := newClient(t)
+	tests := []struct {
+		greeting       *greetpb.Greeting
+		acceptLanguage string
+		want, language string
+	}{
+		{&greetpb.Greeting{FirstName: "Ann", LastName: "Smith"}, "", "Hello Ann Smith", "en"},
+		{&greetpb.Greeting{FirstName: "Ann", Title: "Dr.", PreferredName: "Annie"}, "", "Hello Dr. Annie", "en"},
+		{&greetpb.Greeting{FirstName: "Ann"}, "de-CH, fr;q=0.8", "Hallo Ann", "de"},
+		{&greetpb.Greeting{FirstName: "Ann", Locale: "fr"}, "de", "Bonjour Ann", "fr"},
+		{&greetpb.Greeting{FirstName: "Ann", Locale: "xx"}, "", "Hello Ann", "en"},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.acceptLanguage != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "accept-language", tt.acceptLanguage)
+		}
+		var header metadata.MD
+		res, err := client.Greet(ctx, &greetpb.GreetRequest{Greeting: tt.greeting}, grpc.Header(&header))
+		if err != nil {
+			t.Fatalf("Greet(%v): %v", tt.greeting, err)
+		}
+		if res.Result != tt.want {
+			t.Errorf("Greet(%v) = %q, want %q", tt.greeting, res.Result, tt.want)
+		}
+		if got := header.Get("content-language"); len(got) != 1 || got[0] != tt.language {
+			t.Errorf("Greet(%v) content-language = %v, want %s", tt.greeting, got, tt.language)
+		}
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	client, clock := newClient(t)
+	ctx := context.Background()
+
+	list, err := client.ListTemplates(ctx, &greetpb.ListTemplatesRequest{})
+	if err != nil || len(list.Templates) != 2 {
+		t.Fatalf("ListTemplates = %v, %v, want built-in templates", list, err)
+	}
+
+	created, err := client.CreateTemplate(ctx, &greetpb.CreateTemplateRequest{Template: &greetpb.GreetingTemplate{
+		Name: "formal",
+		Text: "{salutation}, {title} {last_name}.",
+	}})
+	if err != nil || created.Id == "" {
+		t.Fatalf("CreateTemplate = %v, %v", created, err)
+	}
+	list, err = client.ListTemplates(ctx, &greetpb.ListTemplatesRequest{})
+	if err != nil || len(list.Templates) != 3 || list.Templates[2].Id != created.Id {
+		t.Fatalf("ListTemplates after create = %v, %v", list, err)
+	}
+
+	greet := func(g *greetpb.Greeting) (string, error) {
+		res, err := client.Greet(ctx, &greetpb.GreetRequest{Greeting: g})
+		return res.GetResult(), err
+	}
+	got, err := greet(&greetpb.Greeting{FirstName: "Ann", LastName: "Smith", Title: "Dr.", TemplateId: created.Id})
+	if want := "Good morning, Dr. Smith."; err != nil || got != want {
+		t.Errorf("formal greeting = %q, %v, want %q", got, err, want)
+	}
+	// 08:00 UTC is 17:00 in Tokyo
+	got, err = greet(&greetpb.Greeting{FirstName: "Ann", TimeZone: "Asia/Tokyo", Locale: "de", TemplateId: "time-of-day"})
+	if want := "Guten Tag, Ann!"; err != nil || got != want {
+		t.Errorf("time-of-day greeting = %q, %v, want %q", got, err, want)
+	}
+	clock.Advance(12 * time.Hour)
+	got, err = greet(&greetpb.Greeting{FirstName: "Ann", Occasion: "birthday", TemplateId: "occasion"})
+	if want := "Good evening, Ann! Happy birthday!"; err != nil || got != want {
+		t.Errorf("occasion greeting = %q, %v, want %q", got, err, want)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"unknown placeholder", func() error {
+			_, err := client.CreateTemplate(ctx, &greetpb.CreateTemplateRequest{Template: &greetpb.GreetingTemplate{Text: "Hi {nickname}"}})
+			return err
+		}, codes.InvalidArgument},
+		{"empty template", func() error {
+			_, err := client.CreateTemplate(ctx, &greetpb.CreateTemplateRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown template", func() error {
+			_, err := greet(&greetpb.Greeting{TemplateId: "missing"})
+			return err
+		}, codes.NotFound},
+		{"unknown time zone", func() error {
+			_, err := greet(&greetpb.Greeting{TimeZone: "Mars/Olympus"})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); status.Code(err) != tt.want {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func greetMany(client greetpb.GreetServiceClient, req *greetpb.GreeetManyTimesRequest) ([]*greetpb.GreetManyTimesResponse, error) {
+	stream, err := client.GreetManyTimes(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	var res []*greetpb.GreetManyTimesResponse
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, r)
+	}
+}
+
+func TestGreetManyTimes(t *testing.T) {
+	client, clock := newClient(t)
+	ann := &greetpb.Greeting{FirstName: "Ann"}
+
+	res, err := greetMany(client, &greetpb.GreeetManyTimesRequest{Greeting: ann, Count: 3, Interval: durationpb.New(2 * time.Second)})
+	if err != nil || len(res) != 3 {
+		t.Fatalf("GreetManyTimes = %v, %v, want 3 greetings", res, err)
+	}
+	for i, r := range res {
+		if want := fmt.Sprintf("Hello Ann (%d/3)", i+1); r.Result != want {
+			t.Errorf("greeting %d = %q, want %q", i, r.Result, want)
+		}
+	}
+	if got := clock.Slept(); got != 4*time.Second {
+		t.Errorf("waited %v between greetings, want 4s", got)
+	}
+
+	res, err = greetMany(client, &greetpb.GreeetManyTimesRequest{Greeting: ann})
+	if err != nil || len(res) != 10 {
+		t.Errorf("default count: got %d greetings, %v, want 10", len(res), err)
+	}
+
+	for _, req := range []*greetpb.GreeetManyTimesRequest{
+		{Greeting: ann, Count: -1},
+		{Greeting: ann, Count: 1001},
+		{Greeting: ann, Interval: durationpb.New(-time.Second)},
+		{Greeting: ann, Interval: durationpb.New(time.Minute)},
+		{Greeting: &greetpb.Greeting{TimeZone: "Mars/Olympus"}},
+	} {
+		if _, err := greetMany(client, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GreetManyTimes(%v) error = %v, want INVALID_ARGUMENT", req, err)
+		}
+	}
+}
+
+func TestGreetManyTimesResume(t *testing.T) {
+	client, _ := newClient(t)
+	req := &greetpb.GreeetManyTimesRequest{Greeting: &greetpb.Greeting{FirstName: "Ann"}, Count: 4}
+
+	stream, err := client.GreetManyTimes(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GreetManyTimes: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+
+	req.ResumeToken = first.ResumeToken
+	res, err := greetMany(client, req)
+	if err != nil || len(res) != 3 || res[0].Result != "Hello Ann (2/4)" {
+		t.Fatalf("resumed GreetManyTimes = %v, %v", res, err)
+	}
+
+	// resuming after the last greeting ends the stream right away
+	req.ResumeToken = res[2].ResumeToken
+	if res, err := greetMany(client, req); err != nil || len(res) != 0 {
+		t.Errorf("GreetManyTimes after last token = %v, %v", res, err)
+	}
+
+	for _, token := range []string{"garbage!", "YmxvZy1saXN0OjE"} {
+		req.ResumeToken = token
+		if _, err := greetMany(client, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("token %q: error = %v, want INVALID_ARGUMENT", token, err)
+		}
+	}
+	// token beyond count of the request
+	req.ResumeToken = res[2].ResumeToken
+	req.Count = 2
+	if _, err := greetMany(client, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("token beyond count: error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestGreetManyTimesCanceled(t *testing.T) {
+	// real clock, so the stream waits between greetings
+	client := grpctest.Greet(t, greetsvc.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.GreetManyTimes(ctx, &greetpb.GreeetManyTimesRequest{
+		Greeting: &greetpb.Greeting{FirstName: "Ann"},
+		Interval: durationpb.New(10 * time.Second),
+	})
+	if err != nil {
+		t.Fatalf("GreetManyTimes: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("Recv after cancel error = %v, want CANCELED", err)
+	}
+}
+
+func TestLongGreet(t *testing.T) {
+	client, _ := newClient(t)
+	tests := []struct {
+		greetings []*greetpb.Greeting
+		want      string
+	}{
+		{nil, "Hello!"},
+		{[]*greetpb.Greeting{{FirstName: "Ann"}}, "Hello Ann!"},
+		{[]*greetpb.Greeting{{FirstName: "Ann"}, {FirstName: "Bob"}, {FirstName: "Eve"}}, "Hello Ann, Bob and Eve! Welcome, all 3 of you."},
+		{[]*greetpb.Greeting{{FirstName: "Ann", Locale: "de"}, {FirstName: "Bob", Locale: "fr"}}, "Hallo Ann und Bob! Willkommen, alle 2!"},
+	}
+	for _, tt := range tests {
+		stream, err := client.LongGreet(context.Background())
+		if err != nil {
+			t.Fatalf("LongGreet: %v", err)
+		}
+		for _, g := range tt.greetings {
+			if err := stream.Send(&greetpb.LongGreetRequest{Greeting: g}); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+		}
+		res, err := stream.CloseAndRecv()
+		if err != nil || res.Result != tt.want {
+			t.Errorf("LongGreet(%v) = %v, %v, want %q", tt.greetings, res, err, tt.want)
+		}
+	}
+}
+
+func TestGreetWithDeadline(t *testing.T) {
+	// deadlines are real times, so the fake clock starts at real time
+	clock := grpctest.NewClock(time.Now())
+	client := grpctest.Greet(t, greetsvc.Config{Now: clock.Now, Sleep: clock.Sleep})
+	req := &greetpb.GreetRequest{Greeting: &greetpb.Greeting{FirstName: "Ann"}}
+
+	// server default deadline of the method is long enough
+	res, err := client.GreetWithDeadline(context.Background(), req)
+	if err != nil || res.Result != "Hello Ann" {
+		t.Fatalf("GreetWithDeadline = %v, %v", res, err)
+	}
+	if clock.Slept() != 3*time.Second {
+		t.Errorf("greeting took %v, want 3s", clock.Slept())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.GreetWithDeadline(ctx, req); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("short deadline: error = %v, want DEADLINE_EXCEEDED", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("short deadline: call waited for the deadline")
+	}
+
+	// deadline is long enough by real time, but not by the server clock
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	clock.Advance(58 * time.Second)
+	if _, err := client.GreetWithDeadline(ctx, req); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("deadline passed on server clock: error = %v, want DEADLINE_EXCEEDED", err)
+	}
+	if clock.Slept() != 3*time.Second {
+		t.Errorf("deadline passed on server clock: slept %v, want no sleep", clock.Slept()-3*time.Second)
+	}
+}
+
+// room is GreetEveryone stream of one participant.
+type room struct {
+	t      *testing.T
+	stream greetpb.GreetService_GreetEveryoneClient
+}
+
+func join(t *testing.T, client greetpb.GreetServiceClient, name string) *room {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stream, err := client.GreetEveryone(ctx)
+	if err != nil {
+		t.Fatalf("GreetEveryone: %v", err)
+	}
+	r := &room{t: t, stream: stream}
+	r.send(name)
+	return r
+}
+
+func (r *room) send(name string) {
+	r.t.Helper()
+	if err := r.stream.Send(&greetpb.GreetEveryoneRequest{Room: "test", Greeting: &greetpb.Greeting{FirstName: name}}); err != nil {
+		r.t.Fatalf("Send: %v", err)
+	}
+}
+
+// expect receives next message and compares its kind, sender and result.
+func (r *room) expect(kind greetpb.GreetEveryoneResponse_Kind, from, result string) {
+	r.t.Helper()
+	res, err := r.stream.Recv()
+	if err != nil {
+		r.t.Fatalf("Recv: %v", err)
+	}
+	if res.Kind != kind || res.From != from || res.Result != result || res.Room != "test" {
+		r.t.Errorf("got %v, want %v from %s %q", res, kind, from, result)
+	}
+}
+
+func TestGreetEveryone(t *testing.T) {
+	client, _ := newClient(t)
+
+	ann := join(t, client, "Ann")
+	ann.expect(greetpb.GreetEveryoneResponse_JOIN, "Ann", "")
+	ann.expect(greetpb.GreetEveryoneResponse_GREETING, "Ann", "Hello Ann!")
+
+	bob := join(t, client, "Bob")
+	bob.expect(greetpb.GreetEveryoneResponse_JOIN, "Ann", "")
+	bob.expect(greetpb.GreetEveryoneResponse_JOIN, "Bob", "")
+	bob.expect(greetpb.GreetEveryoneResponse_GREETING, "Bob", "Hello Bob!")
+	ann.expect(greetpb.GreetEveryoneResponse_JOIN, "Bob", "")
+	ann.expect(greetpb.GreetEveryoneResponse_GREETING, "Bob", "Hello Bob!")
+
+	ann.send("Eve")
+	ann.expect(greetpb.GreetEveryoneResponse_GREETING, "Ann", "Hello Eve!")
+	bob.expect(greetpb.GreetEveryoneResponse_GREETING, "Ann", "Hello Eve!")
+
+	if err := ann.stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend: %v", err)
+	}
+	if _, err := ann.stream.Recv(); err != io.EOF {
+		t.Errorf("Recv after CloseSend = %v, want EOF", err)
+	}
+	bob.expect(greetpb.GreetEveryoneResponse_LEAVE, "Ann", "")
+}
+
+func TestGreetEveryoneErrors(t *testing.T) {
+	client, _ := newClient(t)
+
+	// stream without greetings ends cleanly
+	stream, err := client.GreetEveryone(context.Background())
+	if err != nil {
+		t.Fatalf("GreetEveryone: %v", err)
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv = %v, want EOF", err)
+	}
+
+	// invalid greeting ends the stream of its sender
+	stream, err = client.GreetEveryone(context.Background())
+	if err != nil {
+		t.Fatalf("GreetEveryone: %v", err)
+	}
+	stream.Send(&greetpb.GreetEveryoneRequest{Greeting: &greetpb.Greeting{TemplateId: "missing"}})
+	for {
+		_, err := stream.Recv()
+		if err == nil {
+			continue
+		}
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Recv error = %v, want NOT_FOUND", err)
+		}
+		break
+	}
+}
+
+// checkedStream reports receiving which outlives the GreetEveryone handler.
+// beforeSend, if set, runs before every sent message and can block or fail
+// it.
+type checkedStream struct {
+	grpc.ServerStream
+	calls      int32
+	receiving  int32
+	returned   int32
+	received   chan struct{} // closed when the handler goroutine starts receiving
+	recvs      chan int32    // numbers of RecvMsg calls, not blocking
+	late       chan<- struct{}
+	beforeSend func(s *checkedStream) error
+}
+
+func (s *checkedStream) RecvMsg(m interface{}) error {
+	n := atomic.AddInt32(&s.calls, 1)
+	if n == 2 {
+		close(s.received)
+	}
+	select {
+	case s.recvs <- n:
+	default:
+	}
+	atomic.AddInt32(&s.receiving, 1)
+	defer atomic.AddInt32(&s.receiving, -1)
+	if atomic.LoadInt32(&s.returned) == 1 {
+		s.reportLate()
+	}
+	return s.ServerStream.RecvMsg(m)
+}
+
+func (s *checkedStream) SendMsg(m interface{}) error {
+	if s.beforeSend != nil {
+		if err := s.beforeSend(s); err != nil {
+			return err
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *checkedStream) reportLate() {
+	select {
+	case s.late <- struct{}{}:
+	default:
+	}
+}
+
+// checkedGreet starts greet service whose streams are checkedStreams and
+// returns channel receiving reports of late receiving.
+func checkedGreet(t *testing.T, cfg greetsvc.Config, beforeSend func(s *checkedStream) error) (greetpb.GreetServiceClient, <-chan struct{}) {
+	svc := greetsvc.New(cfg)
+	late := make(chan struct{}, 1)
+	s := grpc.NewServer(append(svc.ServerOptions(), grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			cs := &checkedStream{ServerStream: ss, received: make(chan struct{}), recvs: make(chan int32, 32), late: late, beforeSend: beforeSend}
+			defer func() {
+				atomic.StoreInt32(&cs.returned, 1)
+				if atomic.LoadInt32(&cs.receiving) != 0 {
+					cs.reportLate()
+				}
+			}()
+			return handler(srv, cs)
+		}))...)
+	greetpb.RegisterGreetServiceServer(s, svc)
+	return greetpb.NewGreetServiceClient(grpctest.Serve(t, s)), late
+}
+
+func TestGreetEveryoneSendFailure(t *testing.T) {
+	failed := make(chan struct{}, 1)
+	client, late := checkedGreet(t, greetsvc.Config{}, func(s *checkedStream) error {
+		<-s.received
+		failed <- struct{}{}
+		return status.Error(codes.Unavailable, "connection reset")
+	})
+
+	stream, err := client.GreetEveryone(context.Background())
+	if err != nil {
+		t.Fatalf("GreetEveryone: %v", err)
+	}
+	if err := stream.Send(&greetpb.GreetEveryoneRequest{Room: "test", Greeting: &greetpb.Greeting{FirstName: "Ann"}}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-failed
+	stream.CloseSend()
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv error = %v, want UNAVAILABLE", err)
+	}
+	select {
+	case <-late:
+		t.Error("GreetEveryone returned while receiving")
+	default:
+	}
+}
+
+func TestGreetEveryoneSlowConsumer(t *testing.T) {
+	// sending to the participant blocks until release
+	slowStream := make(chan *checkedStream, 1)
+	release := make(chan struct{})
+	client, late := checkedGreet(t, greetsvc.Config{RoomBuffer: 4, SlowConsumer: greetsvc.Disconnect}, func(s *checkedStream) error {
+		select {
+		case slowStream <- s:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	slow, err := client.GreetEveryone(context.Background())
+	if err != nil {
+		t.Fatalf("GreetEveryone: %v", err)
+	}
+	send := func() error {
+		return slow.Send(&greetpb.GreetEveryoneRequest{Room: "test", Greeting: &greetpb.Greeting{FirstName: "Slow"}})
+	}
+	for i := 0; i < 5; i++ {
+		if err := send(); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	// own join is being sent and the fifth greeting overflows the buffer,
+	// the goroutine then waits for the next greeting
+	s := <-slowStream
+	for n := range s.recvs {
+		if n == 6 {
+			break
+		}
+	}
+
+	// the join and four buffered greetings are sent, then the handler waits
+	// until the next greeting stops the goroutine
+	close(release)
+	for i := 0; i < 5; i++ {
+		if _, err := slow.Recv(); err != nil {
+			t.Fatalf("Recv %d: %v", i, err)
+		}
+	}
+	send()
+	if _, err := slow.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Recv error = %v, want RESOURCE_EXHAUSTED", err)
+	}
+	if n := atomic.LoadInt32(&s.calls); n != 6 {
+		t.Errorf("stream received %d times, want 6", n)
+	}
+	select {
+	case <-late:
+		t.Error("GreetEveryone returned while receiving")
+	default:
+	}
+}
+
+func BenchmarkGreet(b *testing.B) {
+	client := grpctest.Greet(b, greetsvc.Config{})
+	req := &greetpb.GreetRequest{Greeting: &greetpb.Greeting{FirstName: "Ann", LastName: "Smith", Locale: "de"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.Greet(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGreetManyTimes(b *testing.B) {
+	clock := grpctest.NewClock(morning)
+	client := grpctest.Greet(b, greetsvc.Config{Now: clock.Now, Sleep: clock.Sleep})
+	req := &greetpb.GreeetManyTimesRequest{Greeting: &greetpb.Greeting{FirstName: "Ann"}, Count: 100}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := greetMany(client, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
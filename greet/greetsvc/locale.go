@@ -0,0 +1,33 @@
+package greetsvc
+
+import (
+	"context"
+	"grpc-udemy/greet/greetpb"
+	"grpc-udemy/greet/i18n"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// locale returns catalog locale for greeting g. Locale of g takes precedence
+// over accept-language metadata of the call.
+func locale(ctx context.Context, g *greetpb.Greeting) string {
+	var tags []string
+	if g.GetLocale() != "" {
+		tags = append(tags, g.GetLocale())
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("accept-language") {
+		tags = append(tags, i18n.ParseAcceptLanguage(v)...)
+	}
+	return i18n.Default.Match(tags...)
+}
+
+// languageHeader reports negotiated locale to the client.
+func languageHeader(locale string) metadata.MD {
+	return metadata.Pairs("content-language", locale)
+}
+
+func fullName(g *greetpb.Greeting) string {
+	return strings.TrimSpace(g.GetFirstName() + " " + g.GetLastName())
+}
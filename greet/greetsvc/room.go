@@ -0,0 +1,147 @@
+package greetsvc
+
+import (
+	"fmt"
+	"grpc-udemy/greet/greetpb"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultRoom       = "lobby"
+	defaultRoomBuffer = 64
+)
+
+// SlowConsumerPolicy tells what happens when GreetEveryone participant does
+// not keep up with messages of its room.
+type SlowConsumerPolicy int
+
+const (
+	// DropMessages drops messages which do not fit into participant buffer
+	// and reports their count in the next delivered message.
+	DropMessages SlowConsumerPolicy = iota
+	// Disconnect ends stream of the participant with RESOURCE_EXHAUSTED.
+	Disconnect
+)
+
+// ParseSlowConsumerPolicy parses "drop" or "disconnect".
+func ParseSlowConsumerPolicy(s string) (SlowConsumerPolicy, error) {
+	switch s {
+	case "drop":
+		return DropMessages, nil
+	case "disconnect":
+		return Disconnect, nil
+	}
+	return 0, fmt.Errorf("unknown slow consumer policy %q", s)
+}
+
+// member is participant of a room. Messages are queued in ch, which is closed
+// when member leaves; err is the reason of leaving and can be read after ch
+// is drained.
+type member struct {
+	name string
+	room string
+	ch   chan *greetpb.GreetEveryoneResponse
+
+	// guarded by hub.mu
+	closed  bool
+	dropped int32
+	err     error
+}
+
+// hub fans out messages to members of rooms.
+type hub struct {
+	buffer int
+	policy SlowConsumerPolicy
+
+	mu    sync.Mutex
+	rooms map[string]map[*member]bool
+}
+
+func newHub(buffer int, policy SlowConsumerPolicy) *hub {
+	return &hub{buffer: buffer, policy: policy, rooms: make(map[string]map[*member]bool)}
+}
+
+// join adds new member to room, announces it to others and tells it who is
+// already present.
+func (h *hub) join(room, name string) *member {
+	m := &member{name: name, room: room, ch: make(chan *greetpb.GreetEveryoneResponse, h.buffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	members := h.rooms[room]
+	if members == nil {
+		members = make(map[*member]bool)
+		h.rooms[room] = members
+	}
+	for other := range members {
+		h.deliver(m, &greetpb.GreetEveryoneResponse{Kind: greetpb.GreetEveryoneResponse_JOIN, Room: room, From: other.name})
+	}
+	members[m] = true
+	h.broadcast(room, &greetpb.GreetEveryoneResponse{Kind: greetpb.GreetEveryoneResponse_JOIN, Room: room, From: name})
+	return m
+}
+
+// leave removes m from its room with given reason and announces it to others.
+func (h *hub) leave(m *member, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(m, err)
+}
+
+// publish sends greeting of m to everyone in its room including m. It
+// returns false if m already left.
+func (h *hub) publish(m *member, result string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m.closed {
+		return false
+	}
+	h.broadcast(m.room, &greetpb.GreetEveryoneResponse{Kind: greetpb.GreetEveryoneResponse_GREETING, Room: m.room, From: m.name, Result: result})
+	return true
+}
+
+// broadcast and other methods below require h.mu to be held.
+func (h *hub) broadcast(room string, msg *greetpb.GreetEveryoneResponse) {
+	for m := range h.rooms[room] {
+		h.deliver(m, msg)
+	}
+}
+
+// deliver queues copy of msg without blocking, full queue is handled
+// according to slow consumer policy.
+func (h *hub) deliver(m *member, msg *greetpb.GreetEveryoneResponse) {
+	if m.closed {
+		return
+	}
+	if len(m.ch) == cap(m.ch) {
+		if h.policy == Disconnect {
+			h.remove(m, status.Error(codes.ResourceExhausted, "participant is too slow to receive messages"))
+			return
+		}
+		m.dropped++
+		return
+	}
+	msg = proto.Clone(msg).(*greetpb.GreetEveryoneResponse)
+	msg.Dropped = m.dropped
+	m.dropped = 0
+	m.ch <- msg
+}
+
+func (h *hub) remove(m *member, err error) {
+	if m.closed {
+		return
+	}
+	m.closed = true
+	m.err = err
+	close(m.ch)
+	members := h.rooms[m.room]
+	delete(members, m)
+	if len(members) == 0 {
+		delete(h.rooms, m.room)
+		return
+	}
+	h.broadcast(m.room, &greetpb.GreetEveryoneResponse{Kind: greetpb.GreetEveryoneResponse_LEAVE, Room: m.room, From: m.name})
+}
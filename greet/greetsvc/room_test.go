@@ -0,0 +1,53 @@
+package greetsvc
+
+import (
+	"grpc-udemy/greet/greetpb"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHubDropMessages(t *testing.T) {
+	h := newHub(2, DropMessages)
+	slow := h.join("r", "slow")
+	fast := h.join("r", "fast")
+	for i := 0; i < 5; i++ {
+		h.publish(fast, "hi")
+	}
+
+	// buffer holds own join and join of fast, the rest is dropped
+	for i := 0; i < 2; i++ {
+		if m := <-slow.ch; m.Kind != greetpb.GreetEveryoneResponse_JOIN {
+			t.Fatalf("message %d = %v, want JOIN", i, m)
+		}
+	}
+	h.publish(fast, "again")
+	m := <-slow.ch
+	if m.Result != "again" || m.Dropped != 5 {
+		t.Errorf("got %v, want greeting reporting 5 dropped messages", m)
+	}
+}
+
+func TestHubDisconnect(t *testing.T) {
+	h := newHub(2, Disconnect)
+	slow := h.join("r", "slow")
+	fast := h.join("r", "fast")
+	// both buffers hold two joins, fast keeps up
+	<-fast.ch
+	<-fast.ch
+
+	h.publish(fast, "hi")
+	for range slow.ch {
+	}
+	if status.Code(slow.err) != codes.ResourceExhausted {
+		t.Errorf("slow member error = %v, want RESOURCE_EXHAUSTED", slow.err)
+	}
+	kinds := map[greetpb.GreetEveryoneResponse_Kind]bool{}
+	for i := 0; i < 2; i++ {
+		kinds[(<-fast.ch).Kind] = true
+	}
+	if !kinds[greetpb.GreetEveryoneResponse_GREETING] || !kinds[greetpb.GreetEveryoneResponse_LEAVE] {
+		t.Errorf("fast member got %v, want greeting and leave of slow", kinds)
+	}
+}
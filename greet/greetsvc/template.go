@@ -0,0 +1,155 @@
+package greetsvc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"grpc-udemy/greet/greetpb"
+	"grpc-udemy/greet/i18n"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	maxTemplates      = 1000
+	maxTemplateLength = 1000
+)
+
+var templatePlaceholders = map[string]bool{
+	"salutation":     true,
+	"name":           true,
+	"title":          true,
+	"first_name":     true,
+	"last_name":      true,
+	"preferred_name": true,
+	"occasion":       true,
+}
+
+// builtinTemplates are available on every server.
+var builtinTemplates = []*greetpb.GreetingTemplate{
+	{Id: "time-of-day", Name: "Time of day", Text: "{salutation}, {name}!"},
+	{Id: "occasion", Name: "Occasion", Text: "{salutation}, {name}! Happy {occasion}!"},
+}
+
+// templateStore keeps greeting templates in memory in creation order.
+type templateStore struct {
+	mu        sync.RWMutex
+	templates map[string]*greetpb.GreetingTemplate
+	ids       []string
+}
+
+func newTemplateStore() *templateStore {
+	st := &templateStore{templates: make(map[string]*greetpb.GreetingTemplate)}
+	for _, t := range builtinTemplates {
+		st.templates[t.Id] = t
+		st.ids = append(st.ids, t.Id)
+	}
+	return st
+}
+
+func (st *templateStore) create(t *greetpb.GreetingTemplate) (*greetpb.GreetingTemplate, error) {
+	if t.GetText() == "" {
+		return nil, status.Error(codes.InvalidArgument, "template text is required")
+	}
+	if len(t.Text) > maxTemplateLength {
+		return nil, status.Errorf(codes.InvalidArgument, "template text is longer than %d bytes", maxTemplateLength)
+	}
+	for _, p := range i18n.Placeholders(t.Text) {
+		if !templatePlaceholders[p] {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown placeholder {%s}", p)
+		}
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate template id: %v", err)
+	}
+	t = proto.Clone(t).(*greetpb.GreetingTemplate)
+	t.Id = hex.EncodeToString(b)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.ids) >= maxTemplates {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many templates")
+	}
+	st.templates[t.Id] = t
+	st.ids = append(st.ids, t.Id)
+	return t, nil
+}
+
+func (st *templateStore) get(id string) (*greetpb.GreetingTemplate, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	t, ok := st.templates[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "template %q does not exist", id)
+	}
+	return t, nil
+}
+
+func (st *templateStore) list() []*greetpb.GreetingTemplate {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	res := make([]*greetpb.GreetingTemplate, len(st.ids))
+	for i, id := range st.ids {
+		res[i] = st.templates[id]
+	}
+	return res
+}
+
+// displayName is preferred name, or full name, prefixed with title.
+func displayName(g *greetpb.Greeting) string {
+	name := g.GetPreferredName()
+	if name == "" {
+		name = fullName(g)
+	}
+	if g.GetTitle() != "" {
+		name = g.GetTitle() + " " + name
+	}
+	return name
+}
+
+// salutationKey returns message key of salutation for part of day at t.
+func salutationKey(t time.Time) string {
+	switch h := t.Hour(); {
+	case h >= 5 && h < 12:
+		return i18n.Morning
+	case h >= 12 && h < 18:
+		return i18n.Afternoon
+	case h >= 18 && h < 22:
+		return i18n.Evening
+	}
+	return i18n.Night
+}
+
+// greeting renders greeting of g in locale, using template of g if set and
+// catalog message key otherwise.
+func (s *Server) greeting(g *greetpb.Greeting, locale, key string) (string, error) {
+	zone := time.UTC
+	if g.GetTimeZone() != "" {
+		z, err := time.LoadLocation(g.GetTimeZone())
+		if err != nil {
+			return "", status.Errorf(codes.InvalidArgument, "unknown time zone %q", g.GetTimeZone())
+		}
+		zone = z
+	}
+	name := displayName(g)
+	if g.GetTemplateId() == "" {
+		return i18n.Default.Format(locale, key, 0, map[string]string{"name": name}), nil
+	}
+	t, err := s.templates.get(g.GetTemplateId())
+	if err != nil {
+		return "", err
+	}
+	return i18n.Fill(t.Text, map[string]string{
+		"salutation":     i18n.Default.Format(locale, salutationKey(s.now().In(zone)), 0, nil),
+		"name":           name,
+		"title":          g.GetTitle(),
+		"first_name":     g.GetFirstName(),
+		"last_name":      g.GetLastName(),
+		"preferred_name": g.GetPreferredName(),
+		"occasion":       g.GetOccasion(),
+	}), nil
+}
@@ -0,0 +1,106 @@
+package i18n
+
+// Message keys of greetings.
+const (
+	// Greet greets {name}.
+	Greet = "greet"
+	// GreetMany is {greeting} number {index} of {count}.
+	GreetMany = "greet.many"
+	// GreetAll greets list of {names}, plural form is selected by their count.
+	GreetAll = "greet.all"
+	// GreetShort greets {name} in a conversation.
+	GreetShort = "greet.short"
+
+	// Salutations by part of day.
+	Morning   = "salutation.morning"
+	Afternoon = "salutation.afternoon"
+	Evening   = "salutation.evening"
+	Night     = "salutation.night"
+)
+
+// Default is built-in catalog of greetings. Templates address people without
+// gendered forms.
+var Default = &Catalog{
+	Default: "en",
+	Messages: map[string]map[string]Message{
+		"en": {
+			Greet:      {Other: "Hello {name}"},
+			GreetMany:  {Other: "{greeting} ({index}/{count})"},
+			GreetAll:   {Zero: "Hello!", One: "Hello {names}!", Other: "Hello {names}! Welcome, all {count} of you."},
+			GreetShort: {Other: "Hello {name}!"},
+			"list.and": {Other: "and"},
+			Morning:    {Other: "Good morning"},
+			Afternoon:  {Other: "Good afternoon"},
+			Evening:    {Other: "Good evening"},
+			Night:      {Other: "Hello"},
+		},
+		"de": {
+			Greet:      {Other: "Hallo {name}"},
+			GreetMany:  {Other: "{greeting} ({index}/{count})"},
+			GreetAll:   {Zero: "Hallo!", One: "Hallo {names}!", Other: "Hallo {names}! Willkommen, alle {count}!"},
+			GreetShort: {Other: "Hallo {name}!"},
+			"list.and": {Other: "und"},
+			Morning:    {Other: "Guten Morgen"},
+			Afternoon:  {Other: "Guten Tag"},
+			Evening:    {Other: "Guten Abend"},
+			Night:      {Other: "Hallo"},
+		},
+		"fr": {
+			Greet:      {Other: "Bonjour {name}"},
+			GreetMany:  {Other: "{greeting} ({index}/{count})"},
+			GreetAll:   {Zero: "Bonjour !", One: "Bonjour {names} !", Other: "Bonjour {names} ! Bienvenue à vous {count}."},
+			GreetShort: {Other: "Bonjour {name} !"},
+			"list.and": {Other: "et"},
+			Morning:    {Other: "Bonjour"},
+			Afternoon:  {Other: "Bonjour"},
+			Evening:    {Other: "Bonsoir"},
+			Night:      {Other: "Bonsoir"},
+		},
+		"es": {
+			Greet:      {Other: "Hola {name}"},
+			GreetMany:  {Other: "{greeting} ({index}/{count})"},
+			GreetAll:   {Zero: "¡Hola!", One: "¡Hola {names}!", Other: "¡Hola {names}! Les damos la bienvenida a las {count} personas."},
+			GreetShort: {Other: "¡Hola {name}!"},
+			"list.and": {Other: "y"},
+			Morning:    {Other: "Buenos días"},
+			Afternoon:  {Other: "Buenas tardes"},
+			Evening:    {Other: "Buenas noches"},
+			Night:      {Other: "Buenas noches"},
+		},
+		"pl": {
+			Greet:      {Other: "Cześć {name}"},
+			GreetMany:  {Other: "{greeting} ({index}/{count})"},
+			GreetAll:   {Zero: "Cześć!", One: "Cześć {names}!", Few: "Cześć {names}! Witamy {count} osoby.", Many: "Cześć {names}! Witamy {count} osób.", Other: "Cześć {names}! Witamy {count} osoby."},
+			GreetShort: {Other: "Cześć {name}!"},
+			"list.and": {Other: "i"},
+			Morning:    {Other: "Dzień dobry"},
+			Afternoon:  {Other: "Dzień dobry"},
+			Evening:    {Other: "Dobry wieczór"},
+			Night:      {Other: "Dobry wieczór"},
+		},
+	},
+	Plurals: map[string]PluralRule{
+		"fr": func(n int) string {
+			if n == 0 || n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+		"pl": func(n int) string {
+			switch {
+			case n == 1:
+				return "one"
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return "few"
+			}
+			return "many"
+		},
+	},
+	Fallbacks: map[string]string{
+		// Swiss German
+		"gsw": "de",
+		// Catalan, Galician
+		"ca": "es",
+		"gl": "es",
+	},
+}
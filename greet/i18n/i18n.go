@@ -0,0 +1,252 @@
+// Package i18n renders localized greetings from a message catalog.
+//
+// Locales are BCP 47 language tags. Lookup of a tag walks its fallback chain,
+// e.g. "de-CH-1901" -> "de-CH" -> "de", then explicit fallbacks of the
+// language and finally the default locale of the catalog.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Message is template with plural forms. Placeholders are written as {name}.
+// Forms follow CLDR plural categories, empty forms fall back to Other. Zero
+// is used for count 0 when set, regardless of the language rules.
+type Message struct {
+	Zero, One, Few, Many, Other string
+}
+
+// PluralRule returns plural category of n: "one", "few", "many" or "other".
+type PluralRule func(n int) string
+
+// Catalog holds messages by locale and key.
+type Catalog struct {
+	// Default is locale used when no other locale matches.
+	Default string
+	// Messages maps locale to messages by key.
+	Messages map[string]map[string]Message
+	// Plurals maps language to plural rule, languages without rule use
+	// one for 1 and other for everything else.
+	Plurals map[string]PluralRule
+	// Fallbacks maps language to locale tried before Default, e.g. Swiss
+	// German to German.
+	Fallbacks map[string]string
+}
+
+// chain returns locales to try for tag, most specific first.
+func (c *Catalog) chain(tag string) []string {
+	var res []string
+	seen := map[string]bool{}
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			res = append(res, l)
+		}
+	}
+	tag = Canonical(tag)
+	for t := tag; t != ""; {
+		add(t)
+		i := strings.LastIndexByte(t, '-')
+		if i < 0 {
+			if fb, ok := c.Fallbacks[t]; ok && !seen[fb] {
+				t = fb
+				continue
+			}
+			break
+		}
+		t = t[:i]
+	}
+	return res
+}
+
+// Supports reports whether tag or its fallback chain, excluding Default,
+// has messages in the catalog.
+func (c *Catalog) Supports(tag string) bool {
+	return c.resolve(tag) != ""
+}
+
+func (c *Catalog) resolve(tag string) string {
+	for _, l := range c.chain(tag) {
+		if _, ok := c.Messages[l]; ok {
+			return l
+		}
+	}
+	return ""
+}
+
+// Match returns the first supported locale from tags ordered by preference,
+// or Default if none of them is supported.
+func (c *Catalog) Match(tags ...string) string {
+	for _, t := range tags {
+		if l := c.resolve(t); l != "" {
+			return l
+		}
+	}
+	return c.Default
+}
+
+// Format renders message key for locale with count selecting plural form and
+// args filling placeholders. Placeholder {count} is always available. Missing
+// keys are looked up along the fallback chain of locale.
+func (c *Catalog) Format(locale, key string, count int, args map[string]string) string {
+	chain := append(c.chain(locale), c.Default)
+	for _, l := range chain {
+		m, ok := c.Messages[l][key]
+		if !ok {
+			continue
+		}
+		return fill(m.form(c.plural(l), count), count, args)
+	}
+	return key
+}
+
+func (c *Catalog) plural(locale string) PluralRule {
+	lang := locale
+	if i := strings.IndexByte(lang, '-'); i >= 0 {
+		lang = lang[:i]
+	}
+	if r, ok := c.Plurals[lang]; ok {
+		return r
+	}
+	return func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+func (m Message) form(rule PluralRule, n int) string {
+	if n == 0 && m.Zero != "" {
+		return m.Zero
+	}
+	var s string
+	switch rule(n) {
+	case "one":
+		s = m.One
+	case "few":
+		s = m.Few
+	case "many":
+		s = m.Many
+	}
+	if s == "" {
+		s = m.Other
+	}
+	return s
+}
+
+// Fill replaces placeholders of tmpl with args, unknown placeholders are kept.
+func Fill(tmpl string, args map[string]string) string {
+	return fill(tmpl, 0, args)
+}
+
+// Placeholders returns names of placeholders used in tmpl.
+func Placeholders(tmpl string) []string {
+	var res []string
+	for {
+		i := strings.IndexByte(tmpl, '{')
+		if i < 0 {
+			return res
+		}
+		j := strings.IndexByte(tmpl[i:], '}')
+		if j < 0 {
+			return res
+		}
+		res = append(res, tmpl[i+1:i+j])
+		tmpl = tmpl[i+j+1:]
+	}
+}
+
+func fill(tmpl string, count int, args map[string]string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(tmpl, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(tmpl[i:], '}')
+		if j < 0 {
+			break
+		}
+		b.WriteString(tmpl[:i])
+		name := tmpl[i+1 : i+j]
+		if v, ok := args[name]; ok {
+			b.WriteString(v)
+		} else if name == "count" {
+			b.WriteString(strconv.Itoa(count))
+		} else {
+			b.WriteString(tmpl[i : i+j+1])
+		}
+		tmpl = tmpl[i+j+1:]
+	}
+	b.WriteString(tmpl)
+	return b.String()
+}
+
+// JoinList joins items with commas and message "list.and" of locale before
+// the last item, e.g. "Ann, Bob and Cid".
+func (c *Catalog) JoinList(locale string, items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	and := c.Format(locale, "list.and", 0, nil)
+	return strings.Join(items[:len(items)-1], ", ") + " " + and + " " + items[len(items)-1]
+}
+
+// Canonical normalizes case and separators of tag, e.g. "EN_us" -> "en-US".
+func Canonical(tag string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// ParseAcceptLanguage returns language tags of Accept-Language header value
+// ordered by quality. Wildcard and tags with zero quality are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		f := strings.Split(part, ";")
+		tag := strings.TrimSpace(f[0])
+		q := 1.0
+		for _, p := range f[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				v, err := strconv.ParseFloat(p[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if tag == "" || tag == "*" || q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	res := make([]string, len(tags))
+	for i, t := range tags {
+		res[i] = t.tag
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5, pl;q=0")
+	want := []string{"fr-CH", "fr", "en", "de"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := ParseAcceptLanguage(""); len(got) != 0 {
+		t.Errorf("empty header parsed as %v", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{nil, "en"},
+		{[]string{"de-AT"}, "de"},
+		{[]string{"ja", "fr-CA"}, "fr"},
+		{[]string{"gsw-CH"}, "de"},
+		{[]string{"PL_pl"}, "pl"},
+		{[]string{"ja"}, "en"},
+	}
+	for _, tt := range tests {
+		if got := Default.Match(tt.tags...); got != tt.want {
+			t.Errorf("Match(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	names := func(l string, n ...string) map[string]string {
+		return map[string]string{"names": Default.JoinList(l, n)}
+	}
+	tests := []struct {
+		locale, key string
+		count       int
+		args        map[string]string
+		want        string
+	}{
+		{"en", Greet, 0, map[string]string{"name": "Ann"}, "Hello Ann"},
+		{"de-DE", GreetMany, 3, map[string]string{"greeting": "Hallo Ann", "index": "1"}, "Hallo Ann (1/3)"},
+		{"de", Morning, 0, nil, "Guten Morgen"},
+		{"en", GreetAll, 0, nil, "Hello!"},
+		{"en", GreetAll, 1, names("en", "Ann"), "Hello Ann!"},
+		{"en", GreetAll, 3, names("en", "Ann", "Bob", "Cid"), "Hello Ann, Bob and Cid! Welcome, all 3 of you."},
+		{"pl", GreetAll, 2, names("pl", "Ann", "Bob"), "Cześć Ann i Bob! Witamy 2 osoby."},
+		{"pl", GreetAll, 5, names("pl", "A", "B", "C", "D", "E"), "Cześć A, B, C, D i E! Witamy 5 osób."},
+		{"ja", GreetShort, 0, map[string]string{"name": "Ann"}, "Hello Ann!"},
+		{"en", "missing", 0, nil, "missing"},
+	}
+	for _, tt := range tests {
+		if got := Default.Format(tt.locale, tt.key, tt.count, tt.args); got != tt.want {
+			t.Errorf("Format(%q, %q, %d) = %q, want %q", tt.locale, tt.key, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for in, want := range map[string]string{"EN_us": "en-US", "zh-hant-tw": "zh-Hant-TW", " de ": "de"} {
+		if got := Canonical(in); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := Placeholders("{salutation}, {title} {name}! {")
+	want := []string{"salutation", "title", "name"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := Fill("{a} {b}", map[string]string{"a": "x"}); got != "x {b}" {
+		t.Errorf("Fill returned %q", got)
+	}
+}
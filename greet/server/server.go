@@ -1,93 +1,60 @@
 package main
 
 import (
-	"context"
-	"fmt"
+	"flag"
+	"grpc-udemy/deadline"
 	"grpc-udemy/greet/greetpb"
-	"io"
+	"grpc-udemy/greet/greetsvc"
+	"grpc-udemy/web"
 	"log"
 	"net"
-	"strconv"
+	"net/http"
 	"time"
 
 	"google.golang.org/grpc"
 )
 
-type server struct {
-	greetpb.UnimplementedGreetServiceServer
-}
-
-func (s *server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
-	result := fmt.Sprintf("Hello %s %s", req.Greeting.FirstName, req.Greeting.LastName)
-	res := &greetpb.GreetResponse{
-		Result: result,
-	}
-	return res, nil
-}
+var (
+	addr            = flag.String("addr", ":50051", "gRPC listen address")
+	webAddr         = flag.String("web-addr", ":8081", "gRPC-Web listen address")
+	corsOrigins     = flag.String("cors-origins", "*", "comma separated list of origins allowed to use gRPC-Web")
+	roomBuffer      = flag.Int("room-buffer", 64, "number of messages buffered for each GreetEveryone participant")
+	slowConsumer    = flag.String("slow-consumer", "drop", "what to do when participant buffer is full: drop messages or disconnect the participant")
+	defaultDeadline = flag.Duration("default-deadline", 10*time.Second, "deadline of unary calls which come without one, 0 means none")
+	maxDeadline     = flag.Duration("max-deadline", time.Minute, "longest deadline allowed for unary calls, 0 means no limit")
+)
 
-func (s *server) GreetManyTimes(req *greetpb.GreeetManyTimesRequest, stream greetpb.GreetService_GreetManyTimesServer) error {
-	firstName := req.Greeting.FirstName
-	for i := 0; i < 10; i++ {
-		result := "Hello " + firstName + strconv.Itoa(i)
-		res := &greetpb.GreetManyTimesResponse{
-			Result: result,
-		}
-		stream.Send(res)
-		time.Sleep(1000 * time.Millisecond)
-	}
-	return nil
-}
+func main() {
+	flag.Parse()
 
-func (s *server) LongGreet(stream greetpb.GreetService_LongGreetServer) error {
-	result := ""
-	for {
-		res, err := stream.Recv()
-		if err == io.EOF {
-			return stream.SendAndClose(&greetpb.LongGreetResponse{
-				Result: result,
-			})
-		}
-		if err != nil {
-			log.Fatalf("Error while reading client stream: %v", err)
-		}
-		result += fmt.Sprintf("Hello %s! ", res.Greeting.FirstName)
+	policy, err := greetsvc.ParseSlowConsumerPolicy(*slowConsumer)
+	if err != nil {
+		log.Fatalf("invalid -slow-consumer: %v", err)
 	}
-}
-
-func (s *server) GreetEveryone(stream greetpb.GreetService_GreetEveryoneServer) error {
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			log.Fatalf("failed to read client stream: %v", err)
-			return err
-		}
-		result := "Hello " + req.Greeting.FirstName
-		err = stream.Send(&greetpb.GreetEveryoneResponse{
-			Result: result,
-		})
-		if err != nil {
-			log.Fatalf("Error while sending data to client: %v", err)
-		}
+	if *roomBuffer < 1 {
+		log.Fatalf("-room-buffer must be positive")
 	}
-}
 
-func (s *server) GreetWithDeadline(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
-	time.Sleep(3 * time.Second)
-	return s.Greet(ctx, req)
-}
-
-func main() {
-	lis, err := net.Listen("tcp", ":50051")
+	lis, err := net.Listen("tcp", *addr)
 
 	if err != nil {
 		log.Fatalf("failed to listen %v", err)
 	}
 
-	s := grpc.NewServer()
-	greetpb.RegisterGreetServiceServer(s, &server{})
+	svc := greetsvc.New(greetsvc.Config{
+		RoomBuffer:   *roomBuffer,
+		SlowConsumer: policy,
+		Deadline:     deadline.Limit{Default: *defaultDeadline, Max: *maxDeadline},
+	})
+	s := grpc.NewServer(svc.ServerOptions()...)
+	greetpb.RegisterGreetServiceServer(s, svc)
+
+	ws := web.NewServer(*webAddr, s, web.CorsConfig{AllowedOrigins: web.ParseOrigins(*corsOrigins)})
+	go func() {
+		if err := ws.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("failed to serve gRPC-Web: %v", err)
+		}
+	}()
 
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to server: %v", err)
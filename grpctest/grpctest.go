@@ -0,0 +1,125 @@
+// Package grpctest runs services in process over bufconn, so tests call them
+// through real gRPC clients and interceptors without opening network ports.
+// Dependencies are injected through service configs: blog store defaults to
+// memory, and Clock can replace time of greet and calculator services.
+package grpctest
+
+import (
+	"context"
+	"grpc-udemy/blog/blogpb"
+	"grpc-udemy/blog/blogstore"
+	"grpc-udemy/blog/blogsvc"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/calculatorsvc"
+	"grpc-udemy/greet/greetpb"
+	"grpc-udemy/greet/greetsvc"
+	"grpc-udemy/streaming"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1 << 20
+
+// Serve starts s on in-memory listener and returns client connection to it.
+// Both are closed when the test ends.
+func Serve(t testing.TB, s *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(bufSize)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Greet starts greet service configured by cfg.
+func Greet(t testing.TB, cfg greetsvc.Config) greetpb.GreetServiceClient {
+	t.Helper()
+	svc := greetsvc.New(cfg)
+	s := grpc.NewServer(svc.ServerOptions()...)
+	greetpb.RegisterGreetServiceServer(s, svc)
+	return greetpb.NewGreetServiceClient(Serve(t, s))
+}
+
+// Calculator starts calculator service configured by cfg.
+func Calculator(t testing.TB, cfg calculatorsvc.Config) calculatorpb.CalculatorClient {
+	t.Helper()
+	svc := calculatorsvc.New(cfg)
+	s := grpc.NewServer(svc.ServerOptions()...)
+	calculatorpb.RegisterCalculatorServer(s, svc)
+	return calculatorpb.NewCalculatorClient(Serve(t, s))
+}
+
+// Blog starts blog service configured by cfg, with empty memory store unless
+// cfg sets one.
+func Blog(t testing.TB, cfg blogsvc.Config) blogpb.BlogServiceClient {
+	t.Helper()
+	if cfg.Store == nil {
+		cfg.Store = blogstore.NewMemory()
+	}
+	svc := blogsvc.New(cfg)
+	s := grpc.NewServer(svc.ServerOptions()...)
+	blogpb.RegisterBlogServiceServer(s, svc)
+	return blogpb.NewBlogServiceClient(Serve(t, s))
+}
+
+// Clock is fake clock for Now and Sleep fields of service configs. Sleep
+// advances the clock instead of waiting, so tests of delays run instantly.
+type Clock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept time.Duration
+}
+
+// NewClock creates clock showing now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns current fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleep advances the clock by d unless ctx is done.
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return streaming.Error(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.slept += d
+	return nil
+}
+
+// Slept returns total duration of all sleeps.
+func (c *Clock) Slept() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.slept
+}
@@ -0,0 +1,163 @@
+package grpctest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongodStartTimeout bounds how long Mongod waits for the server to accept
+// connections.
+const mongodStartTimeout = 30 * time.Second
+
+// Mongod is throwaway mongodb server for integration tests. It listens on
+// random local port and keeps data in temporary directory removed when the
+// test ends.
+type Mongod struct {
+	t      testing.TB
+	path   string
+	dir    string
+	port   int
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	output bytes.Buffer
+	done   chan struct{}
+}
+
+// StartMongod starts mongod found in PATH, the test is skipped if there is
+// none or if it runs with -short.
+func StartMongod(t testing.TB) *Mongod {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping mongodb integration test in short mode")
+	}
+	path, err := exec.LookPath("mongod")
+	if err != nil {
+		t.Skip("mongod not found in PATH")
+	}
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	m := &Mongod{t: t, path: path, dir: t.TempDir(), port: port}
+	t.Cleanup(m.Stop)
+	m.Start()
+	return m
+}
+
+func freePort() (int, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port, nil
+}
+
+// URI returns connection string of the server.
+func (m *Mongod) URI() string {
+	return fmt.Sprintf("mongodb://127.0.0.1:%d", m.port)
+}
+
+// Start starts the server again after Stop, with the same port and data.
+// It returns once the server accepts connections.
+func (m *Mongod) Start() {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cmd != nil {
+		return
+	}
+	m.output.Reset()
+	cmd := exec.Command(m.path,
+		"--dbpath", m.dir,
+		"--port", fmt.Sprint(m.port),
+		"--bind_ip", "127.0.0.1",
+		"--nounixsocket",
+	)
+	cmd.Stdout = &m.output
+	cmd.Stderr = &m.output
+	if err := cmd.Start(); err != nil {
+		m.t.Fatalf("failed to start mongod: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	m.cmd, m.done = cmd, done
+
+	if err := m.waitReady(); err != nil {
+		m.stop()
+		m.t.Fatalf("mongod is not ready: %v\n%s", err, m.output.String())
+	}
+}
+
+// waitReady pings the server until it answers or mongodStartTimeout passes.
+func (m *Mongod) waitReady() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongodStartTimeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(m.URI()))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	for {
+		err := client.Ping(ctx, nil)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-m.done:
+			return fmt.Errorf("mongod exited: %v", m.cmd.ProcessState)
+		case <-ctx.Done():
+			return err
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Stop shuts the server down and waits until it exits.
+func (m *Mongod) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stop()
+}
+
+func (m *Mongod) stop() {
+	if m.cmd == nil {
+		return
+	}
+	// SIGINT lets mongod shut down cleanly, so it can start on the same data
+	m.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-m.done:
+	case <-time.After(mongodStartTimeout):
+		m.cmd.Process.Kill()
+		<-m.done
+	}
+	m.cmd = nil
+}
+
+// Client connects to the server, the client is disconnected when the test
+// ends. It keeps working across Stop and Start.
+func (m *Mongod) Client(t testing.TB) *mongo.Client {
+	t.Helper()
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI(m.URI()).
+		SetServerSelectionTimeout(mongodStartTimeout))
+	if err != nil {
+		t.Fatalf("failed to connect to mongod: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	return client
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	// register services which can be called
+	_ "grpc-udemy/blog/blogpb"
+	_ "grpc-udemy/calculator/calculatorpb"
+	_ "grpc-udemy/greet/greetpb"
+	"io"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// caller makes calls of single method with the same requests.
+type caller struct {
+	conn     *grpc.ClientConn
+	method   string
+	desc     *grpc.StreamDesc
+	response protoreflect.MessageType
+	requests []proto.Message
+	// times the requests are sent on client streams
+	repeat int
+}
+
+// findMethod resolves method given as package.Service/Method, with or
+// without leading slash, or as package.Service.Method.
+func findMethod(name string) (protoreflect.MethodDescriptor, error) {
+	fullName := strings.ReplaceAll(strings.TrimPrefix(name, "/"), "/", ".")
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(fullName))
+	if err != nil {
+		return nil, fmt.Errorf("unknown method %q", name)
+	}
+	md, ok := d.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a method", name)
+	}
+	return md, nil
+}
+
+// methodKind describes streaming of md as in gRPC documentation.
+func methodKind(md protoreflect.MethodDescriptor) string {
+	switch {
+	case md.IsStreamingClient() && md.IsStreamingServer():
+		return "bidirectional streaming"
+	case md.IsStreamingClient():
+		return "client streaming"
+	case md.IsStreamingServer():
+		return "server streaming"
+	default:
+		return "unary"
+	}
+}
+
+// listMethods returns all methods which can be called, sorted by name.
+func listMethods() []string {
+	var methods []string
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			sd := fd.Services().Get(i)
+			for j := 0; j < sd.Methods().Len(); j++ {
+				md := sd.Methods().Get(j)
+				methods = append(methods, fmt.Sprintf("%s/%s (%s)", sd.FullName(), md.Name(), methodKind(md)))
+			}
+		}
+		return true
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+// parseRequests parses data as JSON request message, or as JSON array of
+// messages sent in order on client streams.
+func parseRequests(md protoreflect.MethodDescriptor, data string) ([]proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, err
+	}
+	raw := []json.RawMessage{json.RawMessage(data)}
+	if strings.HasPrefix(strings.TrimSpace(data), "[") {
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			return nil, fmt.Errorf("invalid request list: %w", err)
+		}
+		if len(raw) == 0 {
+			return nil, fmt.Errorf("request list is empty")
+		}
+		if len(raw) > 1 && !md.IsStreamingClient() {
+			return nil, fmt.Errorf("%s accepts single request", methodKind(md))
+		}
+	}
+	requests := make([]proto.Message, len(raw))
+	for i, r := range raw {
+		m := mt.New().Interface()
+		if err := protojson.Unmarshal(r, m); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", md.Input().FullName(), err)
+		}
+		requests[i] = m
+	}
+	return requests, nil
+}
+
+func newCaller(conn *grpc.ClientConn, md protoreflect.MethodDescriptor, requests []proto.Message, repeat int) (*caller, error) {
+	response, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+	return &caller{
+		conn:   conn,
+		method: fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name()),
+		desc: &grpc.StreamDesc{
+			StreamName:    string(md.Name()),
+			ClientStreams: md.IsStreamingClient(),
+			ServerStreams: md.IsStreamingServer(),
+		},
+		response: response,
+		requests: requests,
+		repeat:   repeat,
+	}, nil
+}
+
+// call makes one call and returns number of received responses.
+func (c *caller) call(ctx context.Context) (int, error) {
+	if !c.desc.ClientStreams && !c.desc.ServerStreams {
+		if err := c.conn.Invoke(ctx, c.method, c.requests[0], c.response.New().Interface()); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.conn.NewStream(ctx, c.desc, c.method)
+	if err != nil {
+		return 0, err
+	}
+	// requests are sent concurrently with receiving, so bidirectional
+	// streams which answer every request do not block
+	go func() {
+		for i := 0; i < c.repeat; i++ {
+			for _, req := range c.requests {
+				if err := stream.SendMsg(req); err != nil {
+					// the error is returned by RecvMsg
+					return
+				}
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	n := 0
+	for {
+		if err := stream.RecvMsg(c.response.New().Interface()); err != nil {
+			if err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		n++
+	}
+}
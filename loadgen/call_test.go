@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"grpc-udemy/calculator/calculatorpb"
+	"grpc-udemy/calculator/calculatorsvc"
+	"grpc-udemy/grpctest"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFindMethod(t *testing.T) {
+	for _, name := range []string{"calculator.Calculator/Sum", "/calculator.Calculator/Sum", "calculator.Calculator.Sum"} {
+		if md, err := findMethod(name); err != nil || md.FullName() != "calculator.Calculator.Sum" {
+			t.Errorf("findMethod(%q) = %v, %v", name, md, err)
+		}
+	}
+	for _, name := range []string{"calculator.Calculator/Nope", "calculator.Calculator", "calculator.SumRequest"} {
+		if _, err := findMethod(name); err == nil {
+			t.Errorf("findMethod(%q) succeeded", name)
+		}
+	}
+}
+
+func TestParseRequests(t *testing.T) {
+	tests := []struct {
+		method string
+		data   string
+		want   []proto.Message
+	}{
+		{"calculator.Calculator/Sum", `{"x": 3, "y": "4"}`, []proto.Message{&calculatorpb.SumRequest{X: 3, Y: 4}}},
+		{"calculator.Calculator/Sum", `{}`, []proto.Message{&calculatorpb.SumRequest{}}},
+		{"calculator.Calculator/Sum", `[{"x": 1}]`, []proto.Message{&calculatorpb.SumRequest{X: 1}}},
+		{"calculator.Calculator/ComputeAverage", `[{"number": 1}, {"number": 2}]`, []proto.Message{
+			&calculatorpb.AverageRequest{Number: 1},
+			&calculatorpb.AverageRequest{Number: 2},
+		}},
+		// errors
+		{"calculator.Calculator/Sum", `[{"x": 1}, {"x": 2}]`, nil},
+		{"calculator.Calculator/ComputeAverage", `[]`, nil},
+		{"calculator.Calculator/Sum", `{"z": 1}`, nil},
+		{"calculator.Calculator/Sum", `[{"x": 1}`, nil},
+	}
+	for _, tt := range tests {
+		md, err := findMethod(tt.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parseRequests(md, tt.data)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("parseRequests(%s, %s) = %v, want error", tt.method, tt.data, got)
+			}
+			continue
+		}
+		if err != nil || len(got) != len(tt.want) {
+			t.Errorf("parseRequests(%s, %s) = %v, %v, want %v", tt.method, tt.data, got, err, tt.want)
+			continue
+		}
+		for i := range got {
+			if !proto.Equal(got[i], tt.want[i]) {
+				t.Errorf("parseRequests(%s, %s)[%d] = %v, want %v", tt.method, tt.data, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRateInterval(t *testing.T) {
+	tests := []struct {
+		rate    float64
+		want    time.Duration
+		wantErr bool
+	}{
+		{rate: 0, want: 0},
+		{rate: 1, want: time.Second},
+		{rate: 1000, want: time.Millisecond},
+		{rate: 1e9, want: 1},
+		{rate: 2e9, wantErr: true},
+		{rate: -1, wantErr: true},
+		{rate: math.NaN(), wantErr: true},
+		{rate: math.Inf(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := rateInterval(tt.rate)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("rateInterval(%v) = %v, %v, want %v", tt.rate, got, err, tt.want)
+		}
+	}
+}
+
+// countingStream counts requests received by the server.
+type countingStream struct {
+	grpc.ServerStream
+	received *int64
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt64(s.received, 1)
+	}
+	return err
+}
+
+// serveCalculator serves calculator and returns connection to it together
+// with number of requests received on streams.
+func serveCalculator(t *testing.T) (*grpc.ClientConn, *int64) {
+	received := new(int64)
+	svc := calculatorsvc.New(calculatorsvc.Config{})
+	opts := append(svc.ServerOptions(), grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &countingStream{ServerStream: ss, received: received})
+	}))
+	s := grpc.NewServer(opts...)
+	calculatorpb.RegisterCalculatorServer(s, svc)
+	return grpctest.Serve(t, s), received
+}
+
+func newTestCaller(t *testing.T, conn *grpc.ClientConn, method, data string, repeat int) *caller {
+	t.Helper()
+	md, err := findMethod(method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests, err := parseRequests(md, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newCaller(conn, md, requests, repeat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCall(t *testing.T) {
+	conn, received := serveCalculator(t)
+	tests := []struct {
+		method   string
+		data     string
+		repeat   int
+		messages int   // responses
+		sent     int64 // requests received by server on streams
+	}{
+		{"calculator.Calculator/Sum", `{"x": 1, "y": 2}`, 1, 1, 0},
+		{"calculator.Calculator/PrimeNumberDecomposition", `{"number": 360}`, 1, 3, 1},
+		{"calculator.Calculator/ComputeAverage", `{"number": 1}`, 1, 1, 1},
+		{"calculator.Calculator/ComputeAverage", `[{"number": 1}, {"number": 2}]`, 5, 1, 10},
+		{"calculator.Calculator/RunningStatistics", `{"number": 1}`, 1, 1, 1},
+		{"calculator.Calculator/RunningStatistics", `[{"number": 1}, {"number": 2}]`, 5, 10, 10},
+	}
+	for _, tt := range tests {
+		c := newTestCaller(t, conn, tt.method, tt.data, tt.repeat)
+		atomic.StoreInt64(received, 0)
+		if n, err := c.call(context.Background()); err != nil || n != tt.messages {
+			t.Errorf("%s repeated %d times = %d messages, %v, want %d", tt.method, tt.repeat, n, err, tt.messages)
+		}
+		if got := atomic.LoadInt64(received); got != tt.sent {
+			t.Errorf("%s repeated %d times sent %d requests, want %d", tt.method, tt.repeat, got, tt.sent)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	defer func(n int) { *concurrency = n }(*concurrency)
+	*concurrency = 3
+
+	conn, _ := serveCalculator(t)
+	tests := []struct {
+		method  string
+		data    string
+		repeat  int
+		perCall int // responses of one call
+	}{
+		{"calculator.Calculator/ComputeAverage", `[{"number": 1}, {"number": 2}]`, 4, 1},
+		{"calculator.Calculator/RunningStatistics", `[{"number": 1}, {"number": 2}]`, 4, 8},
+	}
+	for _, tt := range tests {
+		c := newTestCaller(t, conn, tt.method, tt.data, tt.repeat)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		res := generate(ctx, []*caller{c}, 0)
+		cancel()
+		calls := len(res.latencies)
+		if calls == 0 {
+			t.Errorf("%s: no calls made", tt.method)
+			continue
+		}
+		if res.codes[codes.OK] != calls {
+			t.Errorf("%s: codes = %v, want all %d calls OK", tt.method, res.codes, calls)
+		}
+		if res.messages != calls*tt.perCall {
+			t.Errorf("%s: %d messages in %d calls, want %d", tt.method, res.messages, calls, calls*tt.perCall)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Command loadgen calls a method of greet, calculator or blog service as fast
+// as -concurrency workers allow, or at -rate calls per second, for -duration
+// and reports throughput and latency percentiles.
+//
+// Usage:
+//
+//	loadgen [flags] <package.Service/Method>
+//
+// Request is given by -data as JSON, client and bidirectional streams send the
+// request, or JSON array of requests, -messages times and close the stream.
+// Latency of streaming call is the time until the server closes the stream.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"grpc-udemy/discovery"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const exitUsage = 64
+
+var (
+	addr        = flag.String("addr", "localhost:50051", "server address, comma separated list of addresses, dns:///name:port or file:///path/to/endpoints")
+	data        = flag.String("data", "{}", "request as JSON, or JSON array of requests for client streams")
+	messages    = flag.Int("messages", 1, "how many times client streams send the requests")
+	concurrency = flag.Int("concurrency", 10, "number of concurrent calls")
+	rate        = flag.Float64("rate", 0, "target calls per second shared by all workers, 0 means as fast as possible")
+	duration    = flag.Duration("duration", 10*time.Second, "how long to generate load")
+	timeout     = flag.Duration("timeout", 0, "call timeout, 0 means no timeout")
+	connections = flag.Int("connections", 1, "number of connections calls are spread over")
+	noCache     = flag.Bool("no-cache", false, "ask server to bypass its response cache")
+	output      = flag.String("output", "text", "output format: text or json")
+	list        = flag.Bool("list", false, "list methods which can be called and exit")
+)
+
+// usageError is returned for invalid flags or arguments.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <package.Service/Method>\n\nFlags:\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+// callWithTimeout makes one call of c limited by -timeout.
+func callWithTimeout(ctx context.Context, c *caller) (int, error) {
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	return c.call(ctx)
+}
+
+// rateInterval returns time between calls made at rate calls per second, or 0
+// for unlimited rate.
+func rateInterval(rate float64) (time.Duration, error) {
+	if !(rate >= 0) {
+		return 0, fmt.Errorf("-rate must not be negative, got %v", rate)
+	}
+	if rate == 0 {
+		return 0, nil
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+	if interval < 1 {
+		return 0, fmt.Errorf("-rate must be at most %v calls per second, got %v", float64(time.Second), rate)
+	}
+	return interval, nil
+}
+
+// generate runs -concurrency workers spread over callers until ctx is done,
+// workers wait for a tick of interval between calls unless it is 0.
+func generate(ctx context.Context, callers []*caller, interval time.Duration) *result {
+	var tokens <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	total := newResult()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func(c *caller) {
+			defer wg.Done()
+			res := newResult()
+			defer func() {
+				mu.Lock()
+				total.merge(res)
+				mu.Unlock()
+			}()
+			for {
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				start := time.Now()
+				n, err := callWithTimeout(ctx, c)
+				elapsed := time.Since(start)
+				// calls cut short by the end of the run are not counted
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+				res.latencies = append(res.latencies, elapsed)
+				res.messages += n
+				res.codes[status.Code(err)]++
+			}
+		}(callers[i%len(callers)])
+	}
+	wg.Wait()
+	return total
+}
+
+func run() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *list {
+		for _, m := range listMethods() {
+			fmt.Println(m)
+		}
+		return nil
+	}
+	if flag.NArg() != 1 {
+		return usageErrorf("method is required")
+	}
+	if *output != "text" && *output != "json" {
+		return usageErrorf("unknown output format %q", *output)
+	}
+	if *concurrency < 1 || *connections < 1 || *messages < 1 || *duration <= 0 {
+		return usageErrorf("-concurrency, -connections, -messages and -duration must be positive")
+	}
+	interval, err := rateInterval(*rate)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	md, err := findMethod(flag.Arg(0))
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	requests, err := parseRequests(md, *data)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	repeat := *messages
+	if !md.IsStreamingClient() {
+		repeat = 1
+	}
+
+	var callers []*caller
+	for i := 0; i < *connections; i++ {
+		conn, err := grpc.Dial(discovery.Target(*addr), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return fmt.Errorf("failed to create dial: %w", err)
+		}
+		defer conn.Close()
+		c, err := newCaller(conn, md, requests, repeat)
+		if err != nil {
+			return err
+		}
+		callers = append(callers, c)
+	}
+
+	ctx := context.Background()
+	if *noCache {
+		ctx = metadata.AppendToOutgoingContext(ctx, "cache-control", "no-cache")
+	}
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	start := time.Now()
+	res := generate(ctx, callers, interval)
+	rep := newReport(res, time.Since(start))
+	rep.Method = callers[0].method
+	rep.Kind = methodKind(md)
+	rep.Concurrency = *concurrency
+	rep.TargetRate = *rate
+	return rep.write(os.Stdout, *output)
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if err := run(); err != nil {
+		log.Println(err)
+		if errors.As(err, &usageError{}) {
+			fmt.Fprintln(flag.CommandLine.Output())
+			usage()
+			os.Exit(exitUsage)
+		}
+		os.Exit(1)
+	}
+}
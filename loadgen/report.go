@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// result collects outcome of calls made by one worker.
+type result struct {
+	latencies []time.Duration
+	messages  int
+	codes     map[codes.Code]int
+}
+
+func newResult() *result {
+	return &result{codes: map[codes.Code]int{}}
+}
+
+func (r *result) merge(o *result) {
+	r.latencies = append(r.latencies, o.latencies...)
+	r.messages += o.messages
+	for c, n := range o.codes {
+		r.codes[c] += n
+	}
+}
+
+// latency summarizes call latencies in milliseconds.
+type latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	P999 float64 `json:"p999"`
+	Max  float64 `json:"max"`
+}
+
+type report struct {
+	Method      string         `json:"method"`
+	Kind        string         `json:"kind"`
+	Duration    float64        `json:"duration_seconds"`
+	Concurrency int            `json:"concurrency"`
+	TargetRate  float64        `json:"target_rate,omitempty"`
+	Calls       int            `json:"calls"`
+	Rate        float64        `json:"rate"`
+	Messages    int            `json:"messages"`
+	MessageRate float64        `json:"message_rate"`
+	Status      map[string]int `json:"status"`
+	LatencyMs   latency        `json:"latency_ms"`
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// percentile returns nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func newReport(r *result, elapsed time.Duration) *report {
+	rep := &report{
+		Duration: elapsed.Seconds(),
+		Calls:    len(r.latencies),
+		Messages: r.messages,
+		Status:   map[string]int{},
+	}
+	if elapsed > 0 {
+		rep.Rate = float64(rep.Calls) / elapsed.Seconds()
+		rep.MessageRate = float64(rep.Messages) / elapsed.Seconds()
+	}
+	for c, n := range r.codes {
+		rep.Status[c.String()] = n
+	}
+
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if len(sorted) > 0 {
+		var total time.Duration
+		for _, d := range sorted {
+			total += d
+		}
+		rep.LatencyMs = latency{
+			Min:  ms(sorted[0]),
+			Mean: ms(total / time.Duration(len(sorted))),
+			P50:  ms(percentile(sorted, 0.5)),
+			P90:  ms(percentile(sorted, 0.9)),
+			P99:  ms(percentile(sorted, 0.99)),
+			P999: ms(percentile(sorted, 0.999)),
+			Max:  ms(sorted[len(sorted)-1]),
+		}
+	}
+	return rep
+}
+
+func (rep *report) write(w io.Writer, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	}
+
+	target := "unlimited"
+	if rep.TargetRate > 0 {
+		target = fmt.Sprintf("%g/s", rep.TargetRate)
+	}
+	statuses := make([]string, 0, len(rep.Status))
+	for s, n := range rep.Status {
+		statuses = append(statuses, fmt.Sprintf("%s %d", s, n))
+	}
+	sort.Strings(statuses)
+	l := rep.LatencyMs
+
+	_, err := fmt.Fprintf(w, `method:      %s (%s)
+duration:    %.2fs
+concurrency: %d, target rate %s
+calls:       %d (%.1f/s)
+messages:    %d (%.1f/s)
+status:      %s
+latency ms:  min %.3f, mean %.3f, p50 %.3f, p90 %.3f, p99 %.3f, p99.9 %.3f, max %.3f
+`,
+		rep.Method, rep.Kind,
+		rep.Duration,
+		rep.Concurrency, target,
+		rep.Calls, rep.Rate,
+		rep.Messages, rep.MessageRate,
+		strings.Join(statuses, ", "),
+		l.Min, l.Mean, l.P50, l.P90, l.P99, l.P999, l.Max,
+	)
+	return err
+}
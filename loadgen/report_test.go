@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestPercentile(t *testing.T) {
+	var thousand []time.Duration
+	for i := 1; i <= 1000; i++ {
+		thousand = append(thousand, time.Duration(i))
+	}
+	tests := []struct {
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{nil, 0.5, 0},
+		{[]time.Duration{7}, 0, 7},
+		{[]time.Duration{7}, 0.999, 7},
+		{[]time.Duration{1, 2, 3, 4}, 0.5, 2},
+		{[]time.Duration{1, 2, 3, 4}, 0.51, 3},
+		{[]time.Duration{1, 2, 3, 4}, 1, 4},
+		{thousand, 0.9, 900},
+		{thousand, 0.999, 999},
+		{thousand[:999], 0.999, 999},
+		{thousand[:100], 0.999, 100},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%d durations, %v) = %v, want %v", len(tt.sorted), tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	res := newResult()
+	res.latencies = []time.Duration{4 * time.Millisecond, time.Millisecond, 3 * time.Millisecond, 2 * time.Millisecond}
+	res.messages = 10
+	res.codes[codes.OK] = 3
+	res.codes[codes.Unavailable] = 1
+
+	rep := newReport(res, 2*time.Second)
+	if rep.Calls != 4 || rep.Rate != 2 || rep.Messages != 10 || rep.MessageRate != 5 {
+		t.Errorf("calls %d (%v/s), messages %d (%v/s), want 4 (2/s) and 10 (5/s)", rep.Calls, rep.Rate, rep.Messages, rep.MessageRate)
+	}
+	if len(rep.Status) != 2 || rep.Status["OK"] != 3 || rep.Status["Unavailable"] != 1 {
+		t.Errorf("status = %v, want 3 OK and 1 Unavailable", rep.Status)
+	}
+	want := latency{Min: 1, Mean: 2.5, P50: 2, P90: 4, P99: 4, P999: 4, Max: 4}
+	if rep.LatencyMs != want {
+		t.Errorf("latency = %+v, want %+v", rep.LatencyMs, want)
+	}
+
+	// nothing completed
+	rep = newReport(newResult(), 0)
+	if rep.Calls != 0 || rep.Rate != 0 || rep.MessageRate != 0 || len(rep.Status) != 0 || rep.LatencyMs != (latency{}) {
+		t.Errorf("empty report = %+v", rep)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	res := newResult()
+	res.latencies = []time.Duration{time.Millisecond}
+	res.codes[codes.OK] = 1
+	rep := newReport(res, time.Second)
+	rep.Method = "/calculator.Calculator/Sum"
+
+	var text bytes.Buffer
+	if err := rep.write(&text, "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/calculator.Calculator/Sum", "target rate unlimited", "calls:       1 (1.0/s)", "status:      OK 1"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report does not contain %q:\n%s", want, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := rep.write(&out, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got report
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil || got.Calls != 1 || got.LatencyMs.Max != 1 {
+		t.Errorf("json report = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,114 @@
+package streaming
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchConfig tells when batch is flushed. Zero values disable the
+// corresponding limit.
+type BatchConfig struct {
+	// MaxItems flushes batch when it holds this many items.
+	MaxItems int
+	// MaxBytes flushes batch before an item would make it larger, item sizes
+	// are reported by Size. Single item larger than MaxBytes is flushed alone.
+	MaxBytes int
+	Size     func(item interface{}) int
+	// Interval flushes batch this long after its first item was added.
+	Interval time.Duration
+}
+
+// Batcher groups items and passes them to flush function. Flush is never
+// called concurrently and errors stop the batcher.
+type Batcher struct {
+	cfg   BatchConfig
+	flush func(items []interface{}) error
+
+	mu    sync.Mutex
+	items []interface{}
+	bytes int
+	timer *time.Timer
+	err   error
+	// stopped drops pending items and keeps timer from flushing
+	stopped bool
+}
+
+// NewBatcher creates batcher passing batches to flush.
+func NewBatcher(cfg BatchConfig, flush func(items []interface{}) error) *Batcher {
+	return &Batcher{cfg: cfg, flush: flush}
+}
+
+// Add adds item, flushing the batch when a limit is reached. It returns
+// error of any failed flush.
+func (b *Batcher) Add(item interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	size := 0
+	if b.cfg.MaxBytes > 0 && b.cfg.Size != nil {
+		size = b.cfg.Size(item)
+		if len(b.items) > 0 && b.bytes+size > b.cfg.MaxBytes {
+			b.flushLocked()
+		}
+	}
+	b.items = append(b.items, item)
+	b.bytes += size
+	if (b.cfg.MaxItems > 0 && len(b.items) >= b.cfg.MaxItems) || (b.cfg.MaxBytes > 0 && b.bytes >= b.cfg.MaxBytes) {
+		b.flushLocked()
+	} else if len(b.items) == 1 && b.cfg.Interval > 0 {
+		b.timer = time.AfterFunc(b.cfg.Interval, b.onTimer)
+	}
+	return b.err
+}
+
+func (b *Batcher) onTimer() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.stopped {
+		b.flushLocked()
+	}
+}
+
+// Flush flushes pending items.
+func (b *Batcher) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+	return b.err
+}
+
+// Close flushes pending items and stops the batcher.
+func (b *Batcher) Close() error {
+	return b.Flush()
+}
+
+// Stop drops pending items and cancels the flush timer. Once it returns,
+// flush is not called anymore, so stream handlers defer it to keep the timer
+// from sending after they returned.
+func (b *Batcher) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.items = nil
+	b.bytes = 0
+	b.stopped = true
+}
+
+func (b *Batcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.items) == 0 || b.err != nil {
+		return
+	}
+	items := b.items
+	b.items = nil
+	b.bytes = 0
+	b.err = b.flush(items)
+}
@@ -0,0 +1,62 @@
+package streaming
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+// Duplex drives client side of bidirectional stream. Produce is run in its
+// own goroutine and sends messages with send; when it returns nil the stream
+// is half-closed. Handle is called with every message received, newMsg
+// allocates them. Cancel has to cancel the context the stream was created
+// with; it is called to abort the exchange when produce or handle fails.
+//
+// Duplex returns when the server ends the stream, without waiting for
+// produce. It returns nil for OK status, otherwise error of produce, handle
+// or the stream, in that order of precedence.
+func Duplex(stream grpc.ClientStream, cancel context.CancelFunc,
+	produce func(send func(m interface{}) error) error,
+	newMsg func() interface{}, handle func(m interface{}) error) error {
+
+	produced := make(chan error, 1)
+	go func() {
+		err := produce(func(m interface{}) error {
+			if err := stream.SendMsg(m); err != nil {
+				// the stream is broken, its status is reported by RecvMsg
+				return io.EOF
+			}
+			return nil
+		})
+		if err != nil && err != io.EOF {
+			// report the error before cancel makes RecvMsg fail
+			produced <- err
+			cancel()
+			return
+		}
+		stream.CloseSend()
+	}()
+
+	var err error
+	for {
+		m := newMsg()
+		if err = stream.RecvMsg(m); err != nil {
+			break
+		}
+		if err = handle(m); err != nil {
+			cancel()
+			break
+		}
+	}
+	// produce may still be blocked on its input, so it is not waited for
+	select {
+	case perr := <-produced:
+		return perr
+	default:
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
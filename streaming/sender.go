@@ -0,0 +1,107 @@
+package streaming
+
+import (
+	"context"
+	"sync"
+)
+
+// Sender sends messages to a stream from a background goroutine through a
+// bounded buffer. Send blocks while the buffer is full, so slow peers slow
+// down producers instead of growing memory. The first send error stops the
+// sender and is returned by all following calls.
+type Sender struct {
+	stream Stream
+	queue  chan interface{}
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewSender starts sender with buffer of given size, buffer smaller than 1
+// is treated as 1. Sender stops when context of the stream is done.
+func NewSender(s Stream, buffer int) *Sender {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ctx, cancel := context.WithCancel(s.Context())
+	snd := &Sender{
+		stream: s,
+		queue:  make(chan interface{}, buffer),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go snd.run()
+	return snd
+}
+
+func (s *Sender) run() {
+	defer close(s.done)
+	for {
+		select {
+		case m, ok := <-s.queue:
+			if !ok {
+				return
+			}
+			if err := s.stream.SendMsg(m); err != nil {
+				s.fail(err)
+				return
+			}
+		case <-s.ctx.Done():
+			s.fail(Error(s.ctx.Err()))
+			return
+		}
+	}
+}
+
+func (s *Sender) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.cancel()
+}
+
+// Err returns error which stopped the sender, if any.
+func (s *Sender) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Context is done when the sender stops, producers can use it to abandon
+// work whose result can no longer be sent.
+func (s *Sender) Context() context.Context {
+	return s.ctx
+}
+
+// Send queues m. It is safe for concurrent use, but not concurrently with
+// Close.
+func (s *Sender) Send(m interface{}) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.queue <- m:
+		return nil
+	case <-s.ctx.Done():
+		// send errors are recorded before cancel, otherwise the stream is done
+		if err := s.Err(); err != nil {
+			return err
+		}
+		return Error(s.ctx.Err())
+	}
+}
+
+// Close waits until queued messages are sent and returns the send error,
+// if any.
+func (s *Sender) Close() error {
+	close(s.queue)
+	<-s.done
+	s.cancel()
+	return s.Err()
+}
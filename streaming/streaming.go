@@ -0,0 +1,56 @@
+// Package streaming provides helpers for gRPC streams shared by all services:
+// context aware sends and waits, bounded asynchronous sending with
+// backpressure, batching by size or time and client side duplex exchange.
+//
+// Helpers accept Stream, which is implemented by both grpc.ServerStream and
+// grpc.ClientStream, so they work with any generated stream type.
+package streaming
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// Stream is the part of grpc.ServerStream and grpc.ClientStream used here.
+type Stream interface {
+	Context() context.Context
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+// Error converts context errors, possibly wrapped, into status errors with
+// CANCELED or DEADLINE_EXCEEDED code. Other errors are returned unchanged.
+func Error(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+// Send sends m unless context of the stream is already done, so producers
+// stop as soon as the peer goes away.
+func Send(s Stream, m interface{}) error {
+	if err := s.Context().Err(); err != nil {
+		return Error(err)
+	}
+	return s.SendMsg(m)
+}
+
+// Sleep waits for d or until ctx is done, in which case it returns status
+// error of ctx.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return Error(ctx.Err())
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return Error(ctx.Err())
+	}
+}
@@ -0,0 +1,321 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream records sent messages and returns queued received ones.
+type fakeStream struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	sent    []interface{}
+	sendErr error
+	block   chan struct{} // when set, SendMsg waits for it
+	recv    chan interface{}
+	closed  bool
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	select {
+	case v, ok := <-s.recv:
+		if !ok {
+			return io.EOF
+		}
+		if err, ok := v.(error); ok {
+			return err
+		}
+		*m.(*int) = v.(int)
+		return nil
+	case <-s.ctx.Done():
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+func (s *fakeStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *fakeStream) Trailer() metadata.MD         { return nil }
+func (s *fakeStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeStream) messages() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]interface{}(nil), s.sent...)
+}
+
+var _ grpc.ClientStream = (*fakeStream)(nil)
+
+func TestSleepCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := Sleep(ctx, time.Hour); status.Code(err) != codes.Canceled {
+		t.Errorf("Sleep returned %v, want CANCELED", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Sleep did not return immediately")
+	}
+	if err := Sleep(context.Background(), 0); err != nil {
+		t.Errorf("Sleep(0) returned %v", err)
+	}
+}
+
+func TestSendCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &fakeStream{ctx: ctx}
+	if err := Send(s, 1); err != nil {
+		t.Fatalf("Send returned %v", err)
+	}
+	cancel()
+	if err := Send(s, 2); status.Code(err) != codes.Canceled {
+		t.Errorf("Send returned %v, want CANCELED", err)
+	}
+	if got := len(s.messages()); got != 1 {
+		t.Errorf("%d messages sent, want 1", got)
+	}
+}
+
+func TestSender(t *testing.T) {
+	s := &fakeStream{ctx: context.Background()}
+	snd := NewSender(s, 2)
+	for i := 0; i < 100; i++ {
+		if err := snd.Send(i); err != nil {
+			t.Fatalf("Send returned %v", err)
+		}
+	}
+	if err := snd.Close(); err != nil {
+		t.Fatalf("Close returned %v", err)
+	}
+	sent := s.messages()
+	if len(sent) != 100 {
+		t.Fatalf("%d messages sent, want 100", len(sent))
+	}
+	for i, m := range sent {
+		if m != i {
+			t.Fatalf("message %d is %v", i, m)
+		}
+	}
+}
+
+func TestSenderBackpressure(t *testing.T) {
+	s := &fakeStream{ctx: context.Background(), block: make(chan struct{})}
+	snd := NewSender(s, 1)
+	// first message is taken by the send goroutine, second fills the buffer
+	snd.Send(1)
+	snd.Send(2)
+	queued := make(chan struct{})
+	go func() {
+		snd.Send(3)
+		close(queued)
+	}()
+	select {
+	case <-queued:
+		t.Fatalf("Send did not block on full buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(s.block)
+	<-queued
+	if err := snd.Close(); err != nil {
+		t.Fatalf("Close returned %v", err)
+	}
+}
+
+func TestSenderError(t *testing.T) {
+	errBroken := errors.New("broken")
+	s := &fakeStream{ctx: context.Background(), sendErr: errBroken}
+	snd := NewSender(s, 1)
+	snd.Send(1)
+	<-snd.Context().Done()
+	if err := snd.Send(2); err != errBroken {
+		t.Errorf("Send returned %v, want %v", err, errBroken)
+	}
+	if err := snd.Close(); err != errBroken {
+		t.Errorf("Close returned %v, want %v", err, errBroken)
+	}
+}
+
+func TestSenderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &fakeStream{ctx: ctx, block: make(chan struct{})}
+	snd := NewSender(s, 1)
+	snd.Send(1)
+	snd.Send(2)
+	cancel()
+	if err := snd.Send(3); status.Code(err) != codes.Canceled {
+		t.Errorf("Send returned %v, want CANCELED", err)
+	}
+	close(s.block)
+}
+
+func TestBatcher(t *testing.T) {
+	var batches [][]interface{}
+	b := NewBatcher(BatchConfig{MaxItems: 3}, func(items []interface{}) error {
+		batches = append(batches, items)
+		return nil
+	})
+	for i := 0; i < 7; i++ {
+		b.Add(i)
+	}
+	b.Close()
+	if len(batches) != 3 || len(batches[0]) != 3 || len(batches[2]) != 1 {
+		t.Errorf("unexpected batches %v", batches)
+	}
+}
+
+func TestBatcherBytes(t *testing.T) {
+	var sizes []int
+	b := NewBatcher(BatchConfig{MaxBytes: 10, Size: func(item interface{}) int { return item.(int) }}, func(items []interface{}) error {
+		n := 0
+		for _, it := range items {
+			n += it.(int)
+		}
+		sizes = append(sizes, n)
+		return nil
+	})
+	for _, n := range []int{4, 4, 4, 20, 1} {
+		b.Add(n)
+	}
+	b.Close()
+	want := []int{8, 4, 20, 1}
+	if len(sizes) != len(want) {
+		t.Fatalf("batch sizes %v, want %v", sizes, want)
+	}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Fatalf("batch sizes %v, want %v", sizes, want)
+		}
+	}
+}
+
+func TestBatcherInterval(t *testing.T) {
+	flushed := make(chan []interface{}, 1)
+	b := NewBatcher(BatchConfig{MaxItems: 100, Interval: 10 * time.Millisecond}, func(items []interface{}) error {
+		flushed <- items
+		return nil
+	})
+	b.Add(1)
+	select {
+	case items := <-flushed:
+		if len(items) != 1 {
+			t.Errorf("flushed %v", items)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("batch was not flushed by timer")
+	}
+	b.Close()
+}
+
+func TestBatcherStop(t *testing.T) {
+	flushed := make(chan []interface{}, 1)
+	b := NewBatcher(BatchConfig{MaxItems: 100, Interval: 10 * time.Millisecond}, func(items []interface{}) error {
+		flushed <- items
+		return nil
+	})
+	b.Add(1)
+	b.Stop()
+	select {
+	case items := <-flushed:
+		t.Errorf("flushed %v after Stop", items)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBatcherError(t *testing.T) {
+	errFlush := errors.New("flush failed")
+	b := NewBatcher(BatchConfig{MaxItems: 1}, func(items []interface{}) error { return errFlush })
+	if err := b.Add(1); err != errFlush {
+		t.Errorf("Add returned %v", err)
+	}
+	if err := b.Add(2); err != errFlush {
+		t.Errorf("Add after failure returned %v", err)
+	}
+}
+
+func TestDuplex(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &fakeStream{ctx: ctx, recv: make(chan interface{}, 3)}
+	s.recv <- 1
+	s.recv <- 2
+	close(s.recv)
+	var got []int
+	err := Duplex(s, cancel, func(send func(interface{}) error) error {
+		return send(1)
+	}, func() interface{} { return new(int) }, func(m interface{}) error {
+		got = append(got, *m.(*int))
+		return nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Errorf("Duplex returned %v, received %v", err, got)
+	}
+}
+
+func TestDuplexProduceError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &fakeStream{ctx: ctx, recv: make(chan interface{})}
+	errInput := errors.New("bad input")
+	err := Duplex(s, cancel, func(send func(interface{}) error) error {
+		return errInput
+	}, func() interface{} { return new(int) }, func(m interface{}) error { return nil })
+	if err != errInput {
+		t.Errorf("Duplex returned %v, want %v", err, errInput)
+	}
+}
+
+func TestDuplexStreamError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &fakeStream{ctx: ctx, recv: make(chan interface{}, 1)}
+	s.recv <- status.Error(codes.InvalidArgument, "bad")
+	block := make(chan struct{})
+	defer close(block)
+	err := Duplex(s, cancel, func(send func(interface{}) error) error {
+		// producer blocked on input must not block Duplex
+		<-block
+		return nil
+	}, func() interface{} { return new(int) }, func(m interface{}) error { return nil })
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Duplex returned %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestResumeToken(t *testing.T) {
+	token := ResumeToken("blog", "42")
+	if got, err := ParseResumeToken("blog", token); err != nil || got != "42" {
+		t.Fatalf("ParseResumeToken = %q, %v, want 42", got, err)
+	}
+	for _, bad := range []string{ResumeToken("greet", "42"), "not base64!", ""} {
+		if _, err := ParseResumeToken("blog", bad); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ParseResumeToken(%q) error = %v, want INVALID_ARGUMENT", bad, err)
+		}
+	}
+}
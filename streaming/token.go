@@ -0,0 +1,29 @@
+package streaming
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResumeToken encodes position of a server stream into an opaque token. Kind
+// names the stream, so tokens of one method are not accepted by another.
+func ResumeToken(kind, position string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + position))
+}
+
+// ParseResumeToken returns position encoded in token by ResumeToken with the
+// same kind. Malformed tokens result in INVALID_ARGUMENT status error.
+func ParseResumeToken(kind, token string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, "malformed resume token")
+	}
+	position := strings.TrimPrefix(string(b), kind+":")
+	if len(position) == len(b) {
+		return "", status.Errorf(codes.InvalidArgument, "resume token is not for %s", kind)
+	}
+	return position, nil
+}
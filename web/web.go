@@ -0,0 +1,65 @@
+// Package web exposes gRPC services to browser clients over gRPC-Web.
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+)
+
+// CorsConfig controls which browser origins are allowed to call the services.
+type CorsConfig struct {
+	// AllowedOrigins is a list of allowed origins, "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedHeaders is a list of extra request headers browser may send.
+	AllowedHeaders []string
+}
+
+// ParseOrigins splits comma separated list of origins.
+func ParseOrigins(origins string) []string {
+	res := make([]string, 0)
+	for _, o := range strings.Split(origins, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+func (c CorsConfig) allowOrigin(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHandler wraps grpc server into http handler which speaks gRPC-Web.
+// Websocket transport is enabled so bidirectional streams (e.g. GreetEveryone)
+// can be used from the browser.
+func NewHandler(s *grpc.Server, cors CorsConfig) http.Handler {
+	headers := cors.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"*"}
+	}
+
+	return grpcweb.WrapServer(s,
+		grpcweb.WithOriginFunc(cors.allowOrigin),
+		grpcweb.WithAllowedRequestHeaders(headers),
+		grpcweb.WithWebsockets(true),
+		grpcweb.WithWebsocketOriginFunc(func(req *http.Request) bool {
+			return cors.allowOrigin(req.Header.Get("Origin"))
+		}),
+	)
+}
+
+// NewServer creates http server serving gRPC-Web requests on addr.
+func NewServer(addr string, s *grpc.Server, cors CorsConfig) *http.Server {
+	return &http.Server{
+		Addr:    addr,
+		Handler: NewHandler(s, cors),
+	}
+}